@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/services"
+	"github.com/joho/godotenv"
+)
+
+// rotate-keys re-encrypts every notification secret and TOTP secret with the
+// master key named by NOTIFICATION_ACTIVE_KEY_ID. Keep the previous key in
+// NOTIFICATION_MASTER_KEYS until this has completed.
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ Warning: No .env file found")
+	}
+	if err := services.InitSecrets(); err != nil {
+		log.Fatal(err)
+	}
+	database.ConnectDatabase()
+
+	updated, err := services.RotateNotificationSecrets()
+	if err != nil {
+		log.Fatalf("❌ Key rotation stopped after %d configs: %v", updated, err)
+	}
+	fmt.Printf("✅ Re-encrypted secrets of %d notification configs\n", updated)
+
+	updated, err = services.RotateTOTPSecrets()
+	if err != nil {
+		log.Fatalf("❌ Key rotation stopped after %d TOTP secrets: %v", updated, err)
+	}
+	fmt.Printf("✅ Re-encrypted TOTP secrets of %d users\n", updated)
+}
@@ -1,65 +1,178 @@
-package main
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-	"fraudy-backend/internal/handlers"
-	"fraudy-backend/internal/middleware"
-	"fraudy-backend/internal/streaming"
-	"github.com/gorilla/mux"
-	"github.com/rs/cors"
-	"github.com/joho/godotenv"
-)
-
-func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️ Warning: No .env file found")
-	}
-	fmt.Println("🔑 Loaded JWT Secret in main.go:", os.Getenv("JWT_SECRET"))
-	ctx := context.Background()
-	streaming.InitRedis()
-	_, err := streaming.RedisClient.Ping(ctx).Result()
-	if err != nil {
-		log.Fatalf("❌ Redis connection failed: %v", err)
-	} else {
-		fmt.Println("✅ Connected to Redis successfully!")
-	}
-	database.ConnectDatabase()
-	if err := database.DB.AutoMigrate(&models.User{}, &models.Alert{}, &models.FraudActivity{}, &models.NotificationConfig{}); err != nil {
-        log.Fatal("Migration failed:", err)
-    }
-	corsOptions := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
-		AllowCredentials: true,
-	})
-
-	go streaming.MonitorNewWallets(ctx)
-
-	r := mux.NewRouter()
-	r.HandleFunc("/register", handlers.RegisterUser).Methods("POST")
-	r.HandleFunc("/login", handlers.LoginUser).Methods("POST")
-	// JWT Authentication Middleware
-	api := r.PathPrefix("/api").Subrouter()
-	api.Use(middleware.JWTAuthMiddleware)
-	api.HandleFunc("/create-alert", handlers.CreateAlert).Methods("POST")
-	api.HandleFunc("/alerts", handlers.GetUserAlerts).Methods("GET")
-	api.HandleFunc("/notification-configs", handlers.GetUserNotificationConfigs).Methods("GET")
-	api.HandleFunc("/notification-configs", handlers.CreateNotificationConfig).Methods("POST")
-	api.HandleFunc("/notification-configs/{id}", handlers.DeleteNotificationConfig).Methods("DELETE")
-	api.HandleFunc("/fraud-activities", handlers.GetFraudActivities).Methods("GET")
-
-	handler := corsOptions.Handler(r)
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-	fmt.Println("🚀 Server running on port", port)
-	log.Fatal(http.ListenAndServe(":"+port, handler))
-}
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/events"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/handlers"
+	"fraudy-backend/internal/middleware"
+	"fraudy-backend/internal/services"
+	"fraudy-backend/internal/streaming"
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️ Warning: No .env file found")
+	}
+	if err := middleware.InitSigningKeys(); err != nil {
+		log.Fatal(err)
+	}
+	if err := services.InitSecrets(); err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	streaming.InitRedis()
+	_, err := streaming.RedisClient.Ping(ctx).Result()
+	if err != nil {
+		log.Fatalf("❌ Redis connection failed: %v", err)
+	} else {
+		fmt.Println("✅ Connected to Redis successfully!")
+	}
+	events.Init(streaming.RedisClient)
+	database.ConnectDatabase()
+	if err := database.DB.AutoMigrate(
+		&models.User{},
+		&models.Organization{},
+		&models.Membership{},
+		&models.Session{},
+		&models.AccountToken{},
+		&models.RecoveryCode{},
+		&models.StellarAccount{},
+		&models.APIKey{},
+		&models.Alert{},
+		&models.FraudActivity{},
+		&models.NotificationConfig{},
+		&models.NotificationPolicy{},
+		&models.NotificationDelivery{},
+		&models.NotificationTemplate{},
+		&models.FraudActivityComment{},
+		&models.FraudActivityStatusChange{},
+		&models.ReportedAddress{},
+		&models.ReportVote{},
+		&models.AddressList{},
+		&models.AddressListEntry{},
+		&models.Payment{},
+		&models.PaymentSync{},
+		&models.ScheduledReport{},
+	); err != nil {
+        log.Fatal("Migration failed:", err)
+    }
+	if err := services.RunStartupBackfills(); err != nil {
+		log.Println(err)
+	}
+	corsOptions := cors.New(cors.Options{
+		AllowedOrigins:   []string{"http://localhost:5173"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Organization-ID"},
+		ExposedHeaders:   []string{"X-Total-Count", "X-Next-Cursor"},
+		AllowCredentials: true,
+	})
+
+	go streaming.MonitorNewWallets(ctx)
+	go services.RunDigestWorker(ctx)
+	go services.RunScheduledReportWorker(ctx)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/.well-known/jwks.json", handlers.GetJWKS).Methods("GET")
+	r.HandleFunc("/register", handlers.RegisterUser).Methods("POST")
+	r.HandleFunc("/login", handlers.LoginUser).Methods("POST")
+	r.HandleFunc("/login/2fa", handlers.LoginTwoFactor).Methods("POST")
+	r.HandleFunc("/auth/stellar", handlers.GetStellarChallenge).Methods("GET")
+	r.HandleFunc("/auth/stellar", handlers.StellarLogin).Methods("POST")
+	r.HandleFunc("/refresh", handlers.RefreshSession).Methods("POST")
+	r.HandleFunc("/verify-email", handlers.VerifyEmail).Methods("POST")
+	r.HandleFunc("/password-reset", handlers.RequestPasswordReset).Methods("POST")
+	r.HandleFunc("/password-reset/confirm", handlers.ConfirmPasswordReset).Methods("POST")
+	// JWT Authentication Middleware
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(middleware.JWTAuthMiddleware)
+	api.Use(middleware.OrganizationMiddleware)
+	api.HandleFunc("/logout", handlers.Logout).Methods("POST")
+	api.HandleFunc("/logout/all", handlers.LogoutAllSessions).Methods("POST")
+	api.HandleFunc("/sessions", handlers.GetSessions).Methods("GET")
+	api.HandleFunc("/sessions/{id}", handlers.RevokeUserSession).Methods("DELETE")
+	api.HandleFunc("/verify-email/resend", handlers.ResendVerificationEmail).Methods("POST")
+	api.HandleFunc("/api-keys", handlers.GetAPIKeys).Methods("GET")
+	api.HandleFunc("/api-keys", handlers.CreateAPIKey).Methods("POST")
+	api.HandleFunc("/api-keys/{id}", handlers.RevokeAPIKey).Methods("DELETE")
+	api.HandleFunc("/stellar-accounts", handlers.GetStellarAccounts).Methods("GET")
+	api.HandleFunc("/stellar-accounts", handlers.LinkStellarAccount).Methods("POST")
+	api.HandleFunc("/stellar-accounts/{account}", handlers.UnlinkStellarAccount).Methods("DELETE")
+	api.HandleFunc("/2fa/enroll", handlers.EnrollTwoFactor).Methods("POST")
+	api.HandleFunc("/2fa/verify", handlers.ConfirmTwoFactor).Methods("POST")
+	api.HandleFunc("/2fa/recovery-codes", handlers.RegenerateRecoveryCodes).Methods("POST")
+	api.HandleFunc("/2fa/disable", handlers.DisableTwoFactor).Methods("POST")
+	api.HandleFunc("/organizations", handlers.GetUserOrganizations).Methods("GET")
+	api.HandleFunc("/organizations", handlers.CreateOrganization).Methods("POST")
+	api.HandleFunc("/organization", handlers.GetCurrentOrganization).Methods("GET")
+	api.HandleFunc("/organization", handlers.UpdateCurrentOrganization).Methods("PUT")
+	api.HandleFunc("/organization/members", handlers.GetOrganizationMembers).Methods("GET")
+	api.HandleFunc("/organization/members", handlers.AddOrganizationMember).Methods("POST")
+	api.HandleFunc("/organization/members/{userID}", handlers.UpdateOrganizationMember).Methods("PUT")
+	api.HandleFunc("/organization/members/{userID}", handlers.RemoveOrganizationMember).Methods("DELETE")
+	api.HandleFunc("/organization/api-keys", handlers.GetOrganizationAPIKeys).Methods("GET")
+	api.HandleFunc("/create-alert", handlers.CreateAlert).Methods("POST")
+	api.HandleFunc("/alerts", handlers.GetUserAlerts).Methods("GET")
+	api.HandleFunc("/alerts/{id}/notification-policies", handlers.GetAlertNotificationPolicies).Methods("GET")
+	api.HandleFunc("/alerts/{id}/notification-policies", handlers.UpsertAlertNotificationPolicy).Methods("PUT")
+	api.HandleFunc("/notification-configs", handlers.GetUserNotificationConfigs).Methods("GET")
+	api.HandleFunc("/notification-configs", handlers.CreateNotificationConfig).Methods("POST")
+	api.HandleFunc("/notification-configs/{id}", handlers.UpdateNotificationConfig).Methods("PUT")
+	api.HandleFunc("/notification-configs/{id}", handlers.DeleteNotificationConfig).Methods("DELETE")
+	api.HandleFunc("/notification-configs/{id}/test", handlers.TestNotificationConfig).Methods("POST")
+	api.HandleFunc("/notification-templates", handlers.GetNotificationTemplates).Methods("GET")
+	api.HandleFunc("/notification-templates", handlers.SaveNotificationTemplate).Methods("PUT")
+	api.HandleFunc("/notification-templates/preview", handlers.PreviewNotificationTemplate).Methods("POST")
+	api.HandleFunc("/notification-templates/{id}", handlers.DeleteNotificationTemplate).Methods("DELETE")
+	api.HandleFunc("/fraud-activities", handlers.GetFraudActivities).Methods("GET")
+	api.HandleFunc("/fraud-activities/export", handlers.ExportFraudActivities).Methods("GET")
+	api.HandleFunc("/events", handlers.StreamUserEvents).Methods("GET")
+	api.HandleFunc("/events/ticket", handlers.CreateEventTicket).Methods("POST")
+	api.HandleFunc("/reports", handlers.CreateReport).Methods("POST")
+	api.HandleFunc("/reports", handlers.GetReports).Methods("GET")
+	api.HandleFunc("/reports/reputation/{address}", handlers.GetAddressReputation).Methods("GET")
+	api.HandleFunc("/reports/{id}/vote", handlers.VoteOnReport).Methods("POST")
+	api.HandleFunc("/reports/{id}/moderate", handlers.ModerateReport).Methods("POST")
+	api.HandleFunc("/risk/{account}", handlers.GetAccountRisk).Methods("GET")
+	api.HandleFunc("/wallets/{account}", handlers.GetWalletDetails).Methods("GET")
+	api.HandleFunc("/wallets/{account}/graph", handlers.GetTransactionGraph).Methods("GET")
+	api.HandleFunc("/stats/activities", handlers.GetActivityStats).Methods("GET")
+	api.HandleFunc("/stats/top-accounts", handlers.GetTopRiskyAccounts).Methods("GET")
+	api.HandleFunc("/stats/notifications", handlers.GetNotificationStats).Methods("GET")
+	api.HandleFunc("/stats/wallets", handlers.GetWalletStats).Methods("GET")
+	api.HandleFunc("/scheduled-reports", handlers.GetScheduledReports).Methods("GET")
+	api.HandleFunc("/scheduled-reports", handlers.CreateScheduledReport).Methods("POST")
+	api.HandleFunc("/scheduled-reports/{id}", handlers.UpdateScheduledReport).Methods("PUT")
+	api.HandleFunc("/scheduled-reports/{id}", handlers.DeleteScheduledReport).Methods("DELETE")
+	api.HandleFunc("/scheduled-reports/{id}/send", handlers.SendScheduledReportNow).Methods("POST")
+	api.HandleFunc("/screen", handlers.ScreenTransaction).Methods("POST")
+	api.HandleFunc("/lists", handlers.GetAddressLists).Methods("GET")
+	api.HandleFunc("/lists", handlers.CreateAddressList).Methods("POST")
+	api.HandleFunc("/lists/{id}", handlers.UpdateAddressList).Methods("PUT")
+	api.HandleFunc("/lists/{id}", handlers.DeleteAddressList).Methods("DELETE")
+	api.HandleFunc("/lists/{id}/entries", handlers.GetAddressListEntries).Methods("GET")
+	api.HandleFunc("/lists/{id}/entries", handlers.AddAddressListEntry).Methods("POST")
+	api.HandleFunc("/lists/{id}/entries/{entryID}", handlers.DeleteAddressListEntry).Methods("DELETE")
+	api.HandleFunc("/lists/{id}/import", handlers.ImportAddressList).Methods("POST")
+	api.HandleFunc("/fraud-activities/{id}", handlers.GetFraudCase).Methods("GET")
+	api.HandleFunc("/fraud-activities/{id}/transition", handlers.TransitionFraudCase).Methods("POST")
+	api.HandleFunc("/fraud-activities/{id}/assignee", handlers.AssignFraudCase).Methods("PUT")
+	api.HandleFunc("/fraud-activities/{id}/comments", handlers.AddFraudCaseComment).Methods("POST")
+	api.HandleFunc("/fraud-activities/{id}/export", handlers.ExportFraudCase).Methods("GET")
+
+	handler := corsOptions.Handler(r)
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	fmt.Println("🚀 Server running on port", port)
+	log.Fatal(http.ListenAndServe(":"+port, handler))
+}
@@ -0,0 +1,109 @@
+// Package access decides what each organization role may do.
+package access
+
+import "fraudy-backend/internal/models"
+
+type Permission string
+
+const (
+	// ViewData covers reading alerts, cases, lists, wallets, stats and exports.
+	ViewData Permission = "view_data"
+	// WorkCases covers transitioning, assigning and commenting on cases.
+	WorkCases Permission = "work_cases"
+	// ManageLists covers creating, editing and importing address lists.
+	ManageLists Permission = "manage_lists"
+	// ScreenTransactions covers pre-submission screening of transactions.
+	ScreenTransactions Permission = "screen_transactions"
+	// ReportAddresses covers filing and voting on community reports.
+	ReportAddresses Permission = "report_addresses"
+	// ManageAlerts covers creating alerts and their notification policies.
+	ManageAlerts Permission = "manage_alerts"
+	// ManageNotifications covers notification configs, templates and
+	// scheduled reports.
+	ManageNotifications Permission = "manage_notifications"
+	// ManageMembers covers adding, removing and changing the role of members.
+	ManageMembers Permission = "manage_members"
+	// ManageAPIKeys covers creating and revoking the organization's API keys.
+	ManageAPIKeys Permission = "manage_api_keys"
+	// ManageOrganization covers renaming the organization and granting the
+	// owner role.
+	ManageOrganization Permission = "manage_organization"
+)
+
+var roleRanks = map[string]int{
+	models.RoleViewer:  1,
+	models.RoleAnalyst: 2,
+	models.RoleAdmin:   3,
+	models.RoleOwner:   4,
+}
+
+// minimumRoles maps each permission to the least privileged role holding it.
+// Every role also holds the permissions of the roles below it.
+var minimumRoles = map[Permission]string{
+	ViewData:            models.RoleViewer,
+	WorkCases:           models.RoleAnalyst,
+	ManageLists:         models.RoleAnalyst,
+	ScreenTransactions:  models.RoleAnalyst,
+	ReportAddresses:     models.RoleAnalyst,
+	ManageAlerts:        models.RoleAdmin,
+	ManageNotifications: models.RoleAdmin,
+	ManageMembers:       models.RoleAdmin,
+	ManageAPIKeys:       models.RoleAdmin,
+	ManageOrganization:  models.RoleOwner,
+}
+
+// ValidRole reports whether role is one of the organization roles.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// Can reports whether a member with role holds permission.
+func Can(role string, permission Permission) bool {
+	minimum, ok := minimumRoles[permission]
+	if !ok {
+		return false
+	}
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[minimum]
+}
+
+// AtLeast reports whether role is at least as privileged as other.
+func AtLeast(role string, other string) bool {
+	return roleRanks[role] >= roleRanks[other]
+}
+
+// Scope limits what an API key may do, on top of its owner's role.
+type Scope string
+
+const (
+	// ScopeReadActivities covers everything under ViewData.
+	ScopeReadActivities Scope = "activities:read"
+	// ScopeManageAlerts covers creating alerts and reading what they found.
+	ScopeManageAlerts Scope = "alerts:manage"
+	// ScopeScreenTransactions covers pre-submission screening.
+	ScopeScreenTransactions Scope = "transactions:screen"
+)
+
+var scopePermissions = map[Scope][]Permission{
+	ScopeReadActivities:     {ViewData},
+	ScopeManageAlerts:       {ViewData, ManageAlerts},
+	ScopeScreenTransactions: {ScreenTransactions},
+}
+
+// ValidScope reports whether scope is one of the API key scopes.
+func ValidScope(scope string) bool {
+	_, ok := scopePermissions[Scope(scope)]
+	return ok
+}
+
+// ScopesAllow reports whether any of scopes grants permission.
+func ScopesAllow(scopes []string, permission Permission) bool {
+	for _, scope := range scopes {
+		for _, granted := range scopePermissions[Scope(scope)] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
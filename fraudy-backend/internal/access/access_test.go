@@ -0,0 +1,46 @@
+package access
+
+import (
+	"testing"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolePermissions(t *testing.T) {
+	assert.True(t, Can(models.RoleViewer, ViewData))
+	assert.False(t, Can(models.RoleViewer, WorkCases))
+
+	assert.True(t, Can(models.RoleAnalyst, WorkCases))
+	assert.True(t, Can(models.RoleAnalyst, ManageLists))
+	assert.False(t, Can(models.RoleAnalyst, ManageAlerts))
+
+	assert.True(t, Can(models.RoleAdmin, ManageAlerts))
+	assert.True(t, Can(models.RoleAdmin, ManageMembers))
+	assert.False(t, Can(models.RoleAdmin, ManageOrganization))
+
+	assert.True(t, Can(models.RoleOwner, ManageOrganization))
+	assert.False(t, Can("", ViewData))
+	assert.False(t, Can("superuser", ViewData))
+}
+
+func TestAtLeast(t *testing.T) {
+	assert.True(t, AtLeast(models.RoleOwner, models.RoleAdmin))
+	assert.True(t, AtLeast(models.RoleAdmin, models.RoleAdmin))
+	assert.False(t, AtLeast(models.RoleAnalyst, models.RoleAdmin))
+	assert.True(t, ValidRole(models.RoleViewer))
+	assert.False(t, ValidRole("superuser"))
+}
+
+func TestScopesAllow(t *testing.T) {
+	assert.True(t, ScopesAllow([]string{string(ScopeReadActivities)}, ViewData))
+	assert.False(t, ScopesAllow([]string{string(ScopeReadActivities)}, ManageAlerts))
+	assert.True(t, ScopesAllow([]string{string(ScopeReadActivities), string(ScopeManageAlerts)}, ManageAlerts))
+	assert.True(t, ScopesAllow([]string{string(ScopeScreenTransactions)}, ScreenTransactions))
+	assert.False(t, ScopesAllow([]string{string(ScopeScreenTransactions)}, ViewData))
+	assert.False(t, ScopesAllow(nil, ViewData))
+	assert.False(t, ScopesAllow([]string{"admin"}, ManageMembers))
+	assert.True(t, ValidScope("alerts:manage"))
+	assert.False(t, ValidScope("alerts:delete"))
+}
@@ -0,0 +1,77 @@
+// Package events fans live updates out to connected users and organizations
+// through Redis pub/sub, so an event published on one backend replica reaches
+// subscribers connected to any other.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	FraudActivityCreated = "fraud_activity"
+	AlertTriggered       = "alert_triggered"
+	StreamStatusChanged  = "stream_status"
+)
+
+type Event struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+	Time time.Time       `json:"time"`
+}
+
+var redisClient *redis.Client
+
+func Init(client *redis.Client) {
+	redisClient = client
+}
+
+func userChannel(userID int) string {
+	return fmt.Sprintf("events:user:%d", userID)
+}
+
+func organizationChannel(organizationID uint) string {
+	return fmt.Sprintf("events:organization:%d", organizationID)
+}
+
+// PublishToUser sends an event to every live connection of userID.
+func PublishToUser(userID int, eventType string, data interface{}) {
+	publish(userChannel(userID), eventType, data)
+}
+
+// PublishToOrganization sends an event to every live connection of the
+// members of organizationID that are watching it.
+func PublishToOrganization(organizationID uint, eventType string, data interface{}) {
+	publish(organizationChannel(organizationID), eventType, data)
+}
+
+func publish(channel string, eventType string, data interface{}) {
+	if redisClient == nil {
+		return
+	}
+
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println("❌ Error encoding event data:", err)
+		return
+	}
+	eventJSON, err := json.Marshal(Event{Type: eventType, Data: dataJSON, Time: time.Now()})
+	if err != nil {
+		fmt.Println("❌ Error encoding event:", err)
+		return
+	}
+
+	if err := redisClient.Publish(context.Background(), channel, eventJSON).Err(); err != nil {
+		fmt.Printf("❌ Error publishing %s event on %s: %v\n", eventType, channel, err)
+	}
+}
+
+// Subscribe subscribes to the events of userID and of the organization they
+// are working in. The caller must Close the returned subscription.
+func Subscribe(ctx context.Context, userID int, organizationID uint) *redis.PubSub {
+	return redisClient.Subscribe(ctx, userChannel(userID), organizationChannel(organizationID))
+}
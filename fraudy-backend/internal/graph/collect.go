@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/streaming"
+	"fraudy-backend/internal/wallets"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	collectPageSize = 200
+	maxCollectPages = 5
+)
+
+func syncTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("GRAPH_SYNC_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// CollectPayments stores the payments of account that are not in Postgres
+// yet. Accounts collected within GRAPH_SYNC_TTL are skipped. The first
+// collection only takes the latest page; later ones continue from the saved
+// cursor.
+func CollectPayments(network string, account string) error {
+	var sync models.PaymentSync
+	database.DB.Where("network = ? AND account = ?", network, account).First(&sync)
+	if sync.ID != 0 && time.Since(sync.SyncedAt) < syncTTL() {
+		return nil
+	}
+
+	client := streaming.HorizonClient(network)
+	request := horizonclient.OperationRequest{ForAccount: account, Limit: collectPageSize, Order: horizonclient.OrderAsc, Cursor: sync.Cursor}
+	if sync.Cursor == "" {
+		request.Order = horizonclient.OrderDesc
+	}
+
+	cursor := sync.Cursor
+	for page := 0; page < maxCollectPages; page++ {
+		result, err := client.Payments(request)
+		if horizonclient.IsNotFoundError(err) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("fetching payments of %s from Horizon: %v", account, err)
+		}
+		records := result.Embedded.Records
+		if len(records) == 0 {
+			break
+		}
+
+		var payments []models.Payment
+		for _, record := range records {
+			op := wallets.ConvertOperation(record)
+			amount, err := strconv.ParseFloat(op.Amount, 64)
+			if err != nil || op.From == "" || op.To == "" {
+				continue
+			}
+			payments = append(payments, models.Payment{
+				Network:         network,
+				OperationID:     op.ID,
+				TransactionHash: op.TransactionHash,
+				Type:            op.Type,
+				FromAccount:     op.From,
+				ToAccount:       op.To,
+				Asset:           op.Asset,
+				Amount:          amount,
+				LedgerCloseTime: op.CreatedAt,
+			})
+		}
+		if len(payments) > 0 {
+			if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&payments).Error; err != nil {
+				return err
+			}
+		}
+
+		if request.Order == horizonclient.OrderDesc {
+			cursor = records[0].PagingToken()
+			break
+		}
+		cursor = records[len(records)-1].PagingToken()
+		if len(records) < collectPageSize {
+			break
+		}
+		request.Cursor = cursor
+	}
+
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "network"}, {Name: "account"}},
+		DoUpdates: clause.AssignmentColumns([]string{"cursor", "synced_at"}),
+	}).Create(&models.PaymentSync{Network: network, Account: account, Cursor: cursor, SyncedAt: time.Now()}).Error
+}
@@ -0,0 +1,232 @@
+// Package graph builds flow-of-funds graphs around an account from the
+// payments collected into Postgres.
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/risk"
+	"fraudy-backend/internal/services"
+)
+
+const (
+	collectConcurrency  = 4  // accounts collected from Horizon at once
+	maxCollectsPerGraph = 50 // accounts collected from Horizon per graph
+)
+
+type Node struct {
+	Account string   `json:"account"`
+	Depth   int      `json:"depth"` // hops from the seed
+	Score   float64  `json:"score"`
+	Level   string   `json:"level"`
+	Flags   []string `json:"flags"` // flags of the organization's fraud activities on the account
+	Lists   []string `json:"lists"` // the organization's lists the account is on
+}
+
+// Edge aggregates all payments of one asset from one account to another.
+type Edge struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Asset   string    `json:"asset"`
+	Amount  float64   `json:"amount"`
+	Count   int       `json:"count"`
+	FirstAt time.Time `json:"first_at"`
+	LastAt  time.Time `json:"last_at"`
+}
+
+type Graph struct {
+	Seed      string `json:"seed"`
+	Network   string `json:"network"`
+	Hops      int    `json:"hops"`
+	Nodes     []Node `json:"nodes"`
+	Edges     []Edge `json:"edges"`
+	Truncated bool   `json:"truncated"` // MaxNodes was reached before all hops were explored
+	// Uncollected counts accounts whose payments were not refreshed from
+	// Horizon because maxCollectsPerGraph was reached; their stored payments
+	// are still used.
+	Uncollected int `json:"uncollected"`
+}
+
+type Options struct {
+	Hops     int
+	MaxNodes int
+	From     *time.Time
+	To       *time.Time
+}
+
+type edgeRow struct {
+	FromAccount string
+	ToAccount   string
+	Asset       string
+	Amount      float64
+	Count       int
+	FirstAt     time.Time
+	LastAt      time.Time
+}
+
+// Build walks the payment graph breadth-first from seed for opts.Hops hops,
+// collecting each account's payments from Horizon before it is expanded. At
+// most maxCollectsPerGraph accounts are collected, collectConcurrency at a
+// time.
+func Build(seed string, network string, organizationID uint, opts Options) (Graph, error) {
+	g := Graph{Seed: seed, Network: network, Hops: opts.Hops, Nodes: []Node{}, Edges: []Edge{}}
+
+	depths := map[string]int{seed: 0}
+	order := []string{seed}
+	edges := make(map[[3]string]Edge)
+	frontier := []string{seed}
+	collected := 0
+
+	for hop := 0; hop < opts.Hops && len(frontier) > 0; hop++ {
+		collect := frontier[:min(len(frontier), maxCollectsPerGraph-collected)]
+		collected += len(collect)
+		g.Uncollected += len(frontier) - len(collect)
+		if err := collectAccounts(network, seed, collect); err != nil {
+			return g, err
+		}
+
+		query := database.DB.Model(&models.Payment{}).
+			Select("from_account, to_account, asset, SUM(amount) AS amount, COUNT(*) AS count, MIN(ledger_close_time) AS first_at, MAX(ledger_close_time) AS last_at").
+			Where("network = ? AND (from_account IN ? OR to_account IN ?)", network, frontier, frontier)
+		if opts.From != nil {
+			query = query.Where("ledger_close_time >= ?", *opts.From)
+		}
+		if opts.To != nil {
+			query = query.Where("ledger_close_time < ?", *opts.To)
+		}
+		var rows []edgeRow
+		if err := query.Group("from_account, to_account, asset").Order("amount DESC").Scan(&rows).Error; err != nil {
+			return g, err
+		}
+
+		var next []string
+		for _, row := range rows {
+			for _, account := range []string{row.FromAccount, row.ToAccount} {
+				if _, seen := depths[account]; seen {
+					continue
+				}
+				if len(depths) >= opts.MaxNodes {
+					g.Truncated = true
+					continue
+				}
+				depths[account] = hop + 1
+				order = append(order, account)
+				next = append(next, account)
+			}
+
+			_, fromSeen := depths[row.FromAccount]
+			_, toSeen := depths[row.ToAccount]
+			if fromSeen && toSeen {
+				edges[[3]string{row.FromAccount, row.ToAccount, row.Asset}] = Edge{
+					From:    row.FromAccount,
+					To:      row.ToAccount,
+					Asset:   row.Asset,
+					Amount:  row.Amount,
+					Count:   row.Count,
+					FirstAt: row.FirstAt,
+					LastAt:  row.LastAt,
+				}
+			}
+		}
+		frontier = next
+	}
+
+	nodes, err := annotate(order, depths, network, organizationID)
+	if err != nil {
+		return g, err
+	}
+	g.Nodes = nodes
+	for _, edge := range edges {
+		g.Edges = append(g.Edges, edge)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool { return g.Edges[i].Amount > g.Edges[j].Amount })
+	return g, nil
+}
+
+// collectAccounts collects the payments of accounts, collectConcurrency at a
+// time. Only a failure to collect seed is returned; the graph continues with
+// the payments already stored for the others.
+func collectAccounts(network string, seed string, accounts []string) error {
+	var (
+		wg      sync.WaitGroup
+		seedErr error
+	)
+	slots := make(chan struct{}, collectConcurrency)
+	for _, account := range accounts {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(account string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := CollectPayments(network, account); err != nil {
+				if account == seed {
+					seedErr = err
+					return
+				}
+				fmt.Printf("⚠️ Graph continues without fresh payments of %s: %v\n", account, err)
+			}
+		}(account)
+	}
+	wg.Wait()
+	return seedErr
+}
+
+// annotate scores every node from Fraudy's own data and attaches the
+// organization's fraud flags on the graph's network and its list memberships.
+func annotate(accounts []string, depths map[string]int, network string, organizationID uint) ([]Node, error) {
+	var flagRows []struct {
+		Account string
+		Flag    string
+	}
+	err := database.DB.Model(&models.FraudActivity{}).Distinct("account", "flag").
+		Where("organization_id = ? AND network = ? AND account IN ?", organizationID, network, accounts).Scan(&flagRows).Error
+	if err != nil {
+		return nil, err
+	}
+	flags := make(map[string][]string)
+	for _, row := range flagRows {
+		flags[row.Account] = append(flags[row.Account], row.Flag)
+	}
+
+	lists := make(map[string][]string)
+	for _, kind := range []string{models.ListKindBlocklist, models.ListKindAllowlist} {
+		matches, err := services.OrganizationListMatches(organizationID, kind, accounts)
+		if err != nil {
+			return nil, err
+		}
+		for account, name := range matches {
+			lists[account] = append(lists[account], name)
+		}
+	}
+
+	assessments, err := risk.AssessLocalBatch(accounts, network)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]Node, 0, len(accounts))
+	for _, account := range accounts {
+		assessment := assessments[account]
+		node := Node{
+			Account: account,
+			Depth:   depths[account],
+			Score:   assessment.Score,
+			Level:   assessment.Level,
+			Flags:   flags[account],
+			Lists:   lists[account],
+		}
+		if node.Flags == nil {
+			node.Flags = []string{}
+		}
+		if node.Lists == nil {
+			node.Lists = []string{}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
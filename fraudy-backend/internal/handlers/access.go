@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"fraudy-backend/internal/access"
+)
+
+// requestScope is the caller and the organization a request acts in.
+type requestScope struct {
+	UserID         int
+	OrganizationID uint
+	Role           string
+}
+
+// authorize returns the request's scope, or answers 401 or 403 and returns
+// false unless the caller's role in the current organization holds permission.
+// API keys also need a scope granting it; organization keys have no role and
+// are limited by their scopes alone.
+func authorize(w http.ResponseWriter, r *http.Request, permission access.Permission) (requestScope, bool) {
+	userID, ok := r.Context().Value("user_id").(int)
+	keyUserID, isAPIKey := r.Context().Value("api_key_user_id").(int)
+	if isAPIKey {
+		userID, ok = keyUserID, true
+	}
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return requestScope{}, false
+	}
+	organizationID, _ := r.Context().Value("organization_id").(uint)
+	role, _ := r.Context().Value("role").(string)
+	if organizationID == 0 {
+		http.Error(w, "Forbidden: You are not a member of any organization", http.StatusForbidden)
+		return requestScope{}, false
+	}
+	if isAPIKey {
+		scopes, _ := r.Context().Value("scopes").([]string)
+		if !access.ScopesAllow(scopes, permission) {
+			http.Error(w, "Forbidden: This API key's scopes do not allow this action", http.StatusForbidden)
+			return requestScope{}, false
+		}
+		if keyOrganizationID, _ := r.Context().Value("api_key_organization_id").(uint); keyOrganizationID != 0 {
+			return requestScope{UserID: userID, OrganizationID: organizationID}, true
+		}
+	}
+	if !access.Can(role, permission) {
+		http.Error(w, "Forbidden: Your role does not allow this action", http.StatusForbidden)
+		return requestScope{}, false
+	}
+	return requestScope{UserID: userID, OrganizationID: organizationID, Role: role}, true
+}
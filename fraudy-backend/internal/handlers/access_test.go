@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorize(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		values     map[string]interface{}
+		permission access.Permission
+		status     int
+		scope      requestScope
+	}{
+		{
+			name:       "no caller",
+			values:     map[string]interface{}{},
+			permission: access.ViewData,
+			status:     http.StatusUnauthorized,
+		},
+		{
+			name:       "no organization",
+			values:     map[string]interface{}{"user_id": 1, "role": models.RoleAdmin},
+			permission: access.ViewData,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "viewer reads",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleViewer},
+			permission: access.ViewData,
+			status:     http.StatusOK,
+			scope:      requestScope{UserID: 1, OrganizationID: 2, Role: models.RoleViewer},
+		},
+		{
+			name:       "viewer works cases",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleViewer},
+			permission: access.WorkCases,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "analyst works cases",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleAnalyst},
+			permission: access.WorkCases,
+			status:     http.StatusOK,
+			scope:      requestScope{UserID: 1, OrganizationID: 2, Role: models.RoleAnalyst},
+		},
+		{
+			name:       "analyst manages members",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleAnalyst},
+			permission: access.ManageMembers,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "admin manages members",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleAdmin},
+			permission: access.ManageMembers,
+			status:     http.StatusOK,
+			scope:      requestScope{UserID: 1, OrganizationID: 2, Role: models.RoleAdmin},
+		},
+		{
+			name:       "admin manages the organization",
+			values:     map[string]interface{}{"user_id": 1, "organization_id": uint(2), "role": models.RoleAdmin},
+			permission: access.ManageOrganization,
+			status:     http.StatusForbidden,
+		},
+		{
+			name: "personal key with the scope",
+			values: map[string]interface{}{"api_key_user_id": 3, "organization_id": uint(2), "role": models.RoleAdmin,
+				"scopes": []string{string(access.ScopeManageAlerts)}},
+			permission: access.ManageAlerts,
+			status:     http.StatusOK,
+			scope:      requestScope{UserID: 3, OrganizationID: 2, Role: models.RoleAdmin},
+		},
+		{
+			name: "personal key without the scope",
+			values: map[string]interface{}{"api_key_user_id": 3, "organization_id": uint(2), "role": models.RoleAdmin,
+				"scopes": []string{string(access.ScopeReadActivities)}},
+			permission: access.ManageAlerts,
+			status:     http.StatusForbidden,
+		},
+		{
+			name: "personal key with the scope but not the role",
+			values: map[string]interface{}{"api_key_user_id": 3, "organization_id": uint(2), "role": models.RoleViewer,
+				"scopes": []string{string(access.ScopeManageAlerts)}},
+			permission: access.ManageAlerts,
+			status:     http.StatusForbidden,
+		},
+		{
+			name: "organization key with the scope",
+			values: map[string]interface{}{"api_key_user_id": 3, "api_key_organization_id": uint(2), "organization_id": uint(2),
+				"scopes": []string{string(access.ScopeScreenTransactions)}},
+			permission: access.ScreenTransactions,
+			status:     http.StatusOK,
+			scope:      requestScope{UserID: 3, OrganizationID: 2},
+		},
+		{
+			name: "organization key on a role-gated permission",
+			values: map[string]interface{}{"api_key_user_id": 3, "api_key_organization_id": uint(2), "organization_id": uint(2),
+				"scopes": []string{string(access.ScopeManageAlerts)}},
+			permission: access.ManageMembers,
+			status:     http.StatusForbidden,
+		},
+	} {
+		ctx := context.Background()
+		for key, value := range test.values {
+			ctx = context.WithValue(ctx, key, value)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/api/alerts", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		scope, ok := authorize(w, r, test.permission)
+		assert.Equal(t, test.status, w.Code, test.name)
+		assert.Equal(t, test.status == http.StatusOK, ok, test.name)
+		assert.Equal(t, test.scope, scope, test.name)
+	}
+}
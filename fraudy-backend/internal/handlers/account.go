@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const minPasswordLength = 8
+
+type TokenRequest struct {
+	Token string `json:"token"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// emailVerificationRequired reports whether unverified users are kept from
+// logging in, set with REQUIRE_EMAIL_VERIFICATION=true.
+func emailVerificationRequired() bool {
+	return os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true"
+}
+
+// VerifyEmail marks the address of the token's user as verified.
+func VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "A token is required", http.StatusBadRequest)
+		return
+	}
+
+	token, err := services.ConsumeAccountToken(req.Token, models.TokenPurposeVerifyEmail)
+	if errors.Is(err, services.ErrInvalidAccountToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error verifying email", http.StatusInternalServerError)
+		return
+	}
+
+	err = database.DB.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", token.UserID).
+		Update("email_verified_at", time.Now()).Error
+	if err != nil {
+		http.Error(w, "Error verifying email", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail sends the caller a new verification link.
+func ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		http.Error(w, "Email is already verified", http.StatusConflict)
+		return
+	}
+	if err := services.SendVerificationEmail(user); err != nil {
+		fmt.Println("❌ Error sending verification email:", err)
+		http.Error(w, "Error sending verification email", http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Verification email sent"})
+}
+
+// RequestPasswordReset emails a reset link if an account uses the address.
+// It answers the same way, and as quickly, either way so it cannot be used
+// to find accounts: the lookup and the email happen after the response.
+func RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		http.Error(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	go func(email string) {
+		var user models.User
+		if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+			return
+		}
+		err := services.SendPasswordResetEmail(user)
+		if errors.Is(err, services.ErrAccountEmailThrottled) {
+			fmt.Printf("⏳ Skipped password reset email to user %d: one was sent moments ago\n", user.ID)
+		} else if err != nil {
+			fmt.Printf("❌ Error sending password reset email to user %d: %v\n", user.ID, err)
+		}
+	}(strings.TrimSpace(req.Email))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "If an account uses this email, a reset link has been sent"})
+}
+
+// ConfirmPasswordReset sets a new password with a reset token and signs the
+// user out of every session.
+func ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "A token is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		http.Error(w, fmt.Sprintf("Password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "Error hashing password", http.StatusInternalServerError)
+		return
+	}
+
+	token, err := services.ConsumeAccountToken(req.Token, models.TokenPurposePasswordReset)
+	if errors.Is(err, services.ErrInvalidAccountToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error resetting password", http.StatusInternalServerError)
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Following the emailed link proves the address as well.
+		updates := map[string]interface{}{"password": string(hashedPassword)}
+		if err := tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+	if err != nil {
+		http.Error(w, "Error resetting password", http.StatusInternalServerError)
+		return
+	}
+	if _, err := services.RevokeUserSessions(token.UserID); err != nil {
+		fmt.Printf("❌ Error revoking sessions of user %d after password reset: %v\n", token.UserID, err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password reset successfully"})
+}
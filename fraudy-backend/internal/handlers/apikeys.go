@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// defaultAPIKeyTTL is how long a key lasts when created without expires_at.
+const defaultAPIKeyTTL = 365 * 24 * time.Hour
+
+type APIKeyRequest struct {
+	Name         string     `json:"name"`
+	Scopes       []string   `json:"scopes"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	Organization bool       `json:"organization"` // create a key for the current organization
+}
+
+type APIKeyResponse struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	Scopes         []string   `json:"scopes"`
+	OrganizationID uint       `json:"organization_id,omitempty"`
+	CreatedBy      int        `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"` // only returned when the key is created
+}
+
+func newAPIKeyResponse(key models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:             key.ID,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		Scopes:         services.APIKeyScopes(key),
+		OrganizationID: key.OrganizationID,
+		CreatedBy:      key.UserID,
+		CreatedAt:      key.CreatedAt,
+		LastUsedAt:     key.LastUsedAt,
+		ExpiresAt:      key.ExpiresAt,
+	}
+}
+
+func writeAPIKeys(w http.ResponseWriter, keys []models.APIKey) {
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, newAPIKeyResponse(key))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetAPIKeys lists the caller's personal API keys.
+func GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := services.UserAPIKeys(userID)
+	if err != nil {
+		http.Error(w, "Error fetching API keys", http.StatusInternalServerError)
+		return
+	}
+	writeAPIKeys(w, keys)
+}
+
+// GetOrganizationAPIKeys lists the API keys of the current organization.
+func GetOrganizationAPIKeys(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageAPIKeys)
+	if !ok {
+		return
+	}
+
+	keys, err := services.OrganizationAPIKeys(scope.OrganizationID)
+	if err != nil {
+		http.Error(w, "Error fetching API keys", http.StatusInternalServerError)
+		return
+	}
+	writeAPIKeys(w, keys)
+}
+
+// CreateAPIKey creates a personal API key, or an organization key for
+// admins. The key itself is only returned in this response. API keys cannot
+// create other keys.
+func CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		http.Error(w, "At least one scope is required", http.StatusBadRequest)
+		return
+	}
+	for _, s := range req.Scopes {
+		if !access.ValidScope(s) {
+			http.Error(w, "Invalid scope: "+s, http.StatusBadRequest)
+			return
+		}
+	}
+	expiresAt := time.Now().Add(defaultAPIKeyTTL)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			http.Error(w, "expires_at must be in the future", http.StatusBadRequest)
+			return
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	key := models.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Scopes:    strings.Join(req.Scopes, ","),
+		ExpiresAt: &expiresAt,
+	}
+	if req.Organization {
+		scope, ok := authorize(w, r, access.ManageAPIKeys)
+		if !ok {
+			return
+		}
+		key.OrganizationID = scope.OrganizationID
+	}
+
+	raw, err := services.CreateAPIKey(&key)
+	if err != nil {
+		http.Error(w, "Error creating API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(CreatedAPIKeyResponse{APIKeyResponse: newAPIKeyResponse(key), Key: raw})
+}
+
+// RevokeAPIKey revokes one of the caller's personal keys, or a key of the
+// current organization for admins.
+func RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	keyID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+	var key models.APIKey
+	err = database.DB.Where("id = ? AND revoked_at IS NULL", keyID).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error fetching API key", http.StatusInternalServerError)
+		return
+	}
+
+	if key.OrganizationID != 0 {
+		scope, ok := authorize(w, r, access.ManageAPIKeys)
+		if !ok {
+			return
+		}
+		if key.OrganizationID != scope.OrganizationID {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+	} else if key.UserID != userID {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := services.RevokeAPIKey(key.ID); err != nil {
+		http.Error(w, "Error revoking API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "API key revoked successfully"})
+}
@@ -1,47 +1,75 @@
-package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-)
-
-// CreateAlert handles creating a new alert
-func CreateAlert(w http.ResponseWriter, r *http.Request) {
-    var req models.Alert
-    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-        http.Error(w, "Invalid request", http.StatusBadRequest)
-        return
-    }
-
-    userID, ok := r.Context().Value("user_id").(int)
-    if !ok {
-        fmt.Println("❌ Could not extract user_id from JWT")
-        http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
-        return
-    }
-    alert := models.Alert{
-        UserID:                 userID, 
-        AlertName:              req.AlertName,
-        RuleType:               req.RuleType,
-        WalletID:               req.WalletID,
-        NotificationPreferences: req.NotificationPreferences,
-        TransactionThreshold: req.TransactionThreshold,
-        TimeFrame:             req.TimeFrame,
-        TransactionStatus:         req.TransactionStatus,
-    }
-    fmt.Printf("📝 Saving Alert: %+v\n", alert)
-    result := database.DB.Create(&alert)
-    if result.Error != nil {
-        fmt.Println("❌ Error saving alert:", result.Error)
-        http.Error(w, "Error saving alert", http.StatusInternalServerError)
-        return
-    }
-
-    fmt.Println("✅ Alert saved successfully:", alert)
-    w.WriteHeader(http.StatusCreated)
-    json.NewEncoder(w).Encode(map[string]string{"message": "Alert created successfully"})
-}
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+// CreateAlert handles creating a new alert in the caller's organization
+func CreateAlert(w http.ResponseWriter, r *http.Request) {
+    scope, ok := authorize(w, r, access.ManageAlerts)
+    if !ok {
+        return
+    }
+
+    var req models.Alert
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        http.Error(w, "Invalid request", http.StatusBadRequest)
+        return
+    }
+
+    if req.Network != "" && req.Network != "testnet" && req.Network != "public" {
+        http.Error(w, "Network must be testnet or public", http.StatusBadRequest)
+        return
+    }
+
+    if req.RuleType == "listPayment" {
+        var list models.AddressList
+        if req.ListID == nil || database.DB.Where("id = ? AND organization_id = ?", *req.ListID, scope.OrganizationID).First(&list).Error != nil {
+            http.Error(w, "listPayment rules need one of your organization's lists in ListID", http.StatusBadRequest)
+            return
+        }
+        if err := checkListPaymentList(list); err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
+    alert := models.Alert{
+        UserID:                 scope.UserID,
+        OrganizationID:         scope.OrganizationID,
+        AlertName:              req.AlertName,
+        RuleType:               req.RuleType,
+        WalletID:               req.WalletID,
+        NotificationPreferences: req.NotificationPreferences,
+        TransactionThreshold: req.TransactionThreshold,
+        TimeFrame:             req.TimeFrame,
+        TransactionStatus:         req.TransactionStatus,
+        Network:               req.Network,
+        ListID:                req.ListID,
+    }
+    fmt.Printf("📝 Saving Alert: %+v\n", alert)
+    result := database.DB.Create(&alert)
+    if result.Error != nil {
+        fmt.Println("❌ Error saving alert:", result.Error)
+        http.Error(w, "Error saving alert", http.StatusInternalServerError)
+        return
+    }
+
+    fmt.Println("✅ Alert saved successfully:", alert)
+    w.WriteHeader(http.StatusCreated)
+    json.NewEncoder(w).Encode(map[string]string{"message": "Alert created successfully"})
+}
+
+// checkListPaymentList rejects lists a listPayment alert cannot watch. Paying
+// an allowlisted account is expected, so only blocklists raise alerts.
+func checkListPaymentList(list models.AddressList) error {
+    if list.Kind != models.ListKindBlocklist {
+        return fmt.Errorf("listPayment rules need a blocklist, list %d is an %s", list.ID, list.Kind)
+    }
+    return nil
+}
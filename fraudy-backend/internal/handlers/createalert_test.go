@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"testing"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPaymentAlertsNeedABlocklist(t *testing.T) {
+	blocklist := models.AddressList{Kind: models.ListKindBlocklist}
+	assert.NoError(t, checkListPaymentList(blocklist))
+
+	allowlist := models.AddressList{Kind: models.ListKindAllowlist}
+	allowlist.ID = 7
+	assert.EqualError(t, checkListPaymentList(allowlist), "listPayment rules need a blocklist, list 7 is an allowlist")
+}
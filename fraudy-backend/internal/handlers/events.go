@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/events"
+	"fraudy-backend/internal/services"
+)
+
+type EventTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CreateEventTicket returns a single-use ticket for opening the event stream
+// with EventSource, which cannot send the access token in a header:
+// GET /api/events?ticket=... with Accept: text/event-stream.
+func CreateEventTicket(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	sessionID, hasSession := r.Context().Value("session_id").(uint)
+	if !ok || !hasSession {
+		http.Error(w, "Unauthorized: Unable to extract user session", http.StatusUnauthorized)
+		return
+	}
+
+	ticket, expiresAt, err := services.IssueStreamTicket(userID, sessionID)
+	if err != nil {
+		http.Error(w, "Error issuing stream ticket", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(EventTicketResponse{Ticket: ticket, ExpiresAt: expiresAt})
+}
+
+// streamStillAuthorized reports whether the caller may keep receiving the
+// organization's events: their session is not revoked and they are still a
+// member. Organization API keys are not tied to a member.
+func streamStillAuthorized(r *http.Request, scope requestScope) bool {
+	if sessionID, ok := r.Context().Value("session_id").(uint); ok && !services.SessionActive(sessionID, scope.UserID) {
+		return false
+	}
+	if keyOrganizationID, _ := r.Context().Value("api_key_organization_id").(uint); keyOrganizationID != 0 {
+		return true
+	}
+	_, err := services.FindMembership(scope.UserID, scope.OrganizationID)
+	return err == nil
+}
+
+// StreamUserEvents pushes the fraud activities, alert triggers and stream
+// status changes of the caller's organization as Server-Sent Events until the
+// client disconnects, or until the caller's session is revoked or they leave
+// the organization, which is checked on every heartbeat.
+func StreamUserEvents(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := r.Context()
+	subscription := events.Subscribe(ctx, scope.UserID, scope.OrganizationID)
+	defer subscription.Close()
+	if _, err := subscription.Receive(ctx); err != nil {
+		http.Error(w, "Error subscribing to events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// Comments keep proxies from closing an idle connection.
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if !streamStillAuthorized(r, scope) {
+				fmt.Printf("🔒 Closing event stream of user %d in organization %d\n", scope.UserID, scope.OrganizationID)
+				return
+			}
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case message, open := <-messages:
+			if !open {
+				return
+			}
+			var event events.Event
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, message.Payload)
+			flusher.Flush()
+		}
+	}
+}
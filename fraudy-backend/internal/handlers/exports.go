@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+const (
+	exportBatchSize = 200
+	maxPDFCases     = 200
+)
+
+var exportContentTypes = map[string]string{
+	"csv":    "text/csv; charset=utf-8",
+	"ndjson": "application/x-ndjson",
+	"pdf":    "application/pdf",
+}
+
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		http.Error(w, "format must be csv, ndjson or pdf", http.StatusBadRequest)
+		return "", false
+	}
+	return format, true
+}
+
+func startExport(w http.ResponseWriter, format string, name string) {
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+}
+
+// ExportFraudActivities exports the fraud activities of the caller's
+// organization matching the usual list filters. CSV has one row per case, NDJSON one full case report
+// per line (with comments, status and notification history), and PDF an
+// evidence report of up to 200 cases.
+func ExportFraudActivities(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+	query, err := fraudActivityQuery(r, scope.OrganizationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := "fraud-activities-" + time.Now().UTC().Format("20060102-150405")
+
+	if format == "pdf" {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			http.Error(w, "Error counting fraud activities", http.StatusInternalServerError)
+			return
+		}
+		if total > maxPDFCases {
+			http.Error(w, fmt.Sprintf("PDF reports are limited to %d cases; narrow the filters or use csv or ndjson", maxPDFCases), http.StatusBadRequest)
+			return
+		}
+		var activities []models.FraudActivity
+		if err := query.Order("created_at ASC").Find(&activities).Error; err != nil {
+			http.Error(w, "Error fetching fraud activities", http.StatusInternalServerError)
+			return
+		}
+		reports, err := services.LoadCaseReports(activities)
+		if err != nil {
+			http.Error(w, "Error building report", http.StatusInternalServerError)
+			return
+		}
+		startExport(w, format, name)
+		if err := services.WriteCasesPDF(w, "Fraud Investigation Report", reports); err != nil {
+			fmt.Println("❌ Error writing PDF export:", err)
+		}
+		return
+	}
+
+	// CSV and NDJSON are streamed batch by batch so large ranges never sit in memory.
+	startExport(w, format, name)
+	csvWriter := csv.NewWriter(w)
+	if format == "csv" {
+		services.WriteCaseCSVHeader(csvWriter)
+	}
+	var activities []models.FraudActivity
+	result := query.Order("id ASC").FindInBatches(&activities, exportBatchSize, func(tx *gorm.DB, batch int) error {
+		reports, err := services.LoadCaseReports(activities)
+		if err != nil {
+			return err
+		}
+		if format == "ndjson" {
+			return services.WriteCasesNDJSON(w, reports)
+		}
+		if err := services.WriteCasesCSV(csvWriter, reports); err != nil {
+			return err
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	})
+	if result.Error != nil {
+		// Headers are already sent; all we can do is stop and log.
+		fmt.Println("❌ Error streaming fraud activity export:", result.Error)
+	}
+	csvWriter.Flush()
+}
+
+// ExportFraudCase exports a single case: CSV gives its timeline, NDJSON the
+// full case report and PDF the evidence report.
+func ExportFraudCase(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+	activity, found := findFraudActivity(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	reports, err := services.LoadCaseReports([]models.FraudActivity{activity})
+	if err != nil {
+		http.Error(w, "Error building report", http.StatusInternalServerError)
+		return
+	}
+
+	startExport(w, format, fmt.Sprintf("case-%d", activity.ID))
+	switch format {
+	case "csv":
+		csvWriter := csv.NewWriter(w)
+		err = services.WriteTimelineCSV(csvWriter, reports[0])
+		csvWriter.Flush()
+	case "ndjson":
+		err = services.WriteCasesNDJSON(w, reports)
+	case "pdf":
+		err = services.WriteCasesPDF(w, fmt.Sprintf("Case %d Evidence Report", activity.ID), reports)
+	}
+	if err != nil {
+		fmt.Println("❌ Error writing case export:", err)
+	}
+}
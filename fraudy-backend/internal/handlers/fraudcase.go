@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type FraudCaseResponse struct {
+	Activity      models.FraudActivity               `json:"activity"`
+	Comments      []models.FraudActivityComment      `json:"comments"`
+	StatusHistory []models.FraudActivityStatusChange `json:"status_history"`
+}
+
+type CaseTransitionRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+type CaseAssigneeRequest struct {
+	AssigneeID *uint `json:"assignee_id"`
+}
+
+type CaseCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// findFraudActivity loads the activity in the URL if it belongs to
+// organizationID.
+func findFraudActivity(w http.ResponseWriter, r *http.Request, organizationID uint) (models.FraudActivity, bool) {
+	var activity models.FraudActivity
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], organizationID).First(&activity)
+	if result.Error != nil {
+		http.Error(w, "Fraud activity not found or unauthorized", http.StatusNotFound)
+		return activity, false
+	}
+	return activity, true
+}
+
+// GetFraudCase returns a fraud activity together with its comments and status history.
+func GetFraudCase(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	activity, found := findFraudActivity(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	response := FraudCaseResponse{Activity: activity}
+	database.DB.Where("fraud_activity_id = ?", activity.ID).Order("created_at ASC").Find(&response.Comments)
+	database.DB.Where("fraud_activity_id = ?", activity.ID).Order("created_at ASC").Find(&response.StatusHistory)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func TransitionFraudCase(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.WorkCases)
+	if !ok {
+		return
+	}
+
+	activity, found := findFraudActivity(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	var req CaseTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	err := services.TransitionFraudActivity(&activity, scope.UserID, strings.ToLower(req.Status), req.Note)
+	var invalid services.ErrInvalidTransition
+	if errors.As(err, &invalid) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activity)
+}
+
+func AssignFraudCase(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.WorkCases)
+	if !ok {
+		return
+	}
+
+	activity, found := findFraudActivity(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	var req CaseAssigneeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if req.AssigneeID != nil {
+		if _, err := services.FindMembership(int(*req.AssigneeID), scope.OrganizationID); err != nil {
+			http.Error(w, "Assignee is not a member of this organization", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := database.DB.Model(&activity).Update("assignee_id", req.AssigneeID).Error; err != nil {
+		http.Error(w, "Error assigning case", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activity)
+}
+
+func AddFraudCaseComment(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.WorkCases)
+	if !ok {
+		return
+	}
+
+	activity, found := findFraudActivity(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	var req CaseCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Body) == "" {
+		http.Error(w, "Comment body is required", http.StatusBadRequest)
+		return
+	}
+
+	comment := models.FraudActivityComment{
+		FraudActivityID: activity.ID,
+		UserID:          scope.UserID,
+		Body:            req.Body,
+	}
+	if err := database.DB.Create(&comment).Error; err != nil {
+		http.Error(w, "Error saving comment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(comment)
+}
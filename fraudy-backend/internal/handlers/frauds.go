@@ -1,22 +1,68 @@
-package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-)
-
-func GetFraudActivities(w http.ResponseWriter, r *http.Request) {
-	var fraudActivities []models.FraudActivity
-	result := database.DB.Order("created_at DESC").Find(&fraudActivities)
-	if result.Error != nil {
-		http.Error(w, "Failed to fetch fraud activities", http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(fraudActivities)
-	fmt.Println("✅ Fraud activities fetched successfully!")
-}
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+var fraudActivitySorts = map[string]sortField[models.FraudActivity]{
+	"created_at":    {"created_at", func(a models.FraudActivity) interface{} { return a.CreatedAt }},
+	"account":       {"account", func(a models.FraudActivity) interface{} { return a.Account }},
+	"type":          {"type", func(a models.FraudActivity) interface{} { return a.Type }},
+	"flag":          {"flag", func(a models.FraudActivity) interface{} { return a.Flag }},
+	"status":        {"status", func(a models.FraudActivity) interface{} { return a.Status }},
+	"failure_count": {"failure_count", func(a models.FraudActivity) interface{} { return a.FailureCount }},
+}
+
+// fraudActivityQuery applies the account, rule_type, flag, status, network,
+// from and to filters to the fraud activities of an organization.
+func fraudActivityQuery(r *http.Request, organizationID uint) (*gorm.DB, error) {
+	params := r.URL.Query()
+	query := database.DB.Model(&models.FraudActivity{}).Where("organization_id = ?", organizationID)
+	if account := params.Get("account"); account != "" {
+		query = query.Where("account = ?", account)
+	}
+	if ruleType := params.Get("rule_type"); ruleType != "" {
+		query = query.Where("type = ?", ruleType)
+	}
+	if flag := params.Get("flag"); flag != "" {
+		query = query.Where("flag = ?", flag)
+	}
+	if status := params.Get("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if network := params.Get("network"); network != "" {
+		query = query.Where("network = ?", network)
+	}
+	return applyDateRange(query, r, "created_at")
+}
+
+// GetFraudActivities lists the fraud activities of the caller's organization. It supports the
+// filters of fraudActivityQuery as well as sort, limit and cursor pagination.
+func GetFraudActivities(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	query, err := fraudActivityQuery(r, scope.OrganizationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fraudActivities, err := paginate(w, r, query, fraudActivitySorts, "-created_at", func(a models.FraudActivity) uint { return a.ID })
+	if err != nil {
+		writeListError(w, err, "Failed to fetch fraud activities")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fraudActivities)
+	fmt.Println("✅ Fraud activities fetched successfully!")
+}
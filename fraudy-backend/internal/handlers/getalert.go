@@ -1,32 +1,61 @@
-package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-)
-
-func GetUserAlerts(w http.ResponseWriter, r *http.Request) {
-    userID, ok := r.Context().Value("user_id").(int)
-    if !ok {
-        fmt.Println("❌ Could not extract user_id from context") // Debugging
-        http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
-        return
-    }
-    var alerts []models.Alert
-    result := database.DB.Where("user_id = ?", userID).Find(&alerts)
-
-    if result.Error != nil {
-        fmt.Println("❌ Error fetching alerts:", result.Error) // Debugging
-        http.Error(w, "Error fetching alerts", http.StatusInternalServerError)
-        return
-    }
-
-    // Send response
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(alerts)
-}
-
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+var alertSorts = map[string]sortField[models.Alert]{
+    "created_at": {"created_at", func(a models.Alert) interface{} { return a.CreatedAt }},
+    "alert_name": {"alert_name", func(a models.Alert) interface{} { return a.AlertName }},
+    "wallet_id":  {"wallet_id", func(a models.Alert) interface{} { return a.WalletID }},
+    "rule_type":  {"rule_type", func(a models.Alert) interface{} { return a.RuleType }},
+    "flag":       {"flag", func(a models.Alert) interface{} { return a.Flag }},
+}
+
+// GetUserAlerts lists the alerts of the caller's organization with the same
+// filters, sorting and cursor pagination as GetFraudActivities. status filters
+// on TransactionStatus.
+func GetUserAlerts(w http.ResponseWriter, r *http.Request) {
+    scope, ok := authorize(w, r, access.ViewData)
+    if !ok {
+        return
+    }
+    params := r.URL.Query()
+    query := database.DB.Model(&models.Alert{}).Where("organization_id = ?", scope.OrganizationID)
+    if account := params.Get("account"); account != "" {
+        query = query.Where("wallet_id = ?", account)
+    }
+    if ruleType := params.Get("rule_type"); ruleType != "" {
+        query = query.Where("rule_type = ?", ruleType)
+    }
+    if flag := params.Get("flag"); flag != "" {
+        query = query.Where("flag = ?", flag)
+    }
+    if status := params.Get("status"); status != "" {
+        query = query.Where("transaction_status = ?", status == "true")
+    }
+    if network := params.Get("network"); network != "" {
+        query = query.Where("network = ?", network)
+    }
+    query, err := applyDateRange(query, r, "created_at")
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusBadRequest)
+        return
+    }
+
+    alerts, err := paginate(w, r, query, alertSorts, "-created_at", func(a models.Alert) uint { return a.ID })
+    if err != nil {
+        writeListError(w, err, "Error fetching alerts")
+        return
+    }
+
+    // Send response
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(alerts)
+}
+
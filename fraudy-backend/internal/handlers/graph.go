@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/graph"
+	"github.com/gorilla/mux"
+	"github.com/stellar/go/strkey"
+)
+
+const (
+	maxGraphHops     = 3
+	maxGraphNodes    = 500
+	defaultGraphHops = 2
+	defaultGraphSize = 100
+)
+
+// GetTransactionGraph returns the directed payment graph around an account.
+// Query parameters: network, hops (1-3), max_nodes, and a from/to date range.
+func GetTransactionGraph(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	account := mux.Vars(r)["account"]
+	if !strkey.IsValidEd25519PublicKey(account) {
+		http.Error(w, "Account must be a valid Stellar account ID", http.StatusBadRequest)
+		return
+	}
+	params := r.URL.Query()
+	network := params.Get("network")
+	if network == "" {
+		network = "testnet"
+	}
+	if network != "testnet" && network != "public" {
+		http.Error(w, "Network must be testnet or public", http.StatusBadRequest)
+		return
+	}
+
+	opts := graph.Options{Hops: defaultGraphHops, MaxNodes: defaultGraphSize}
+	if value := params.Get("hops"); value != "" {
+		hops, err := strconv.Atoi(value)
+		if err != nil || hops < 1 || hops > maxGraphHops {
+			http.Error(w, "hops must be between 1 and 3", http.StatusBadRequest)
+			return
+		}
+		opts.Hops = hops
+	}
+	if value := params.Get("max_nodes"); value != "" {
+		maxNodes, err := strconv.Atoi(value)
+		if err != nil || maxNodes < 1 {
+			http.Error(w, "max_nodes must be a positive number", http.StatusBadRequest)
+			return
+		}
+		opts.MaxNodes = min(maxNodes, maxGraphNodes)
+	}
+	if value := params.Get("from"); value != "" {
+		from, err := parseTimeParam(value)
+		if err != nil {
+			http.Error(w, "invalid from date: "+value, http.StatusBadRequest)
+			return
+		}
+		opts.From = &from
+	}
+	if value := params.Get("to"); value != "" {
+		to, err := parseTimeParam(value)
+		if err != nil {
+			http.Error(w, "invalid to date: "+value, http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(value, "T") {
+			to = to.Add(24 * time.Hour)
+		}
+		opts.To = &to
+	}
+
+	g, err := graph.Build(account, network, scope.OrganizationID, opts)
+	if err != nil {
+		http.Error(w, "Error building transaction graph: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
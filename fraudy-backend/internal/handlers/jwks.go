@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fraudy-backend/internal/middleware"
+)
+
+// GetJWKS publishes the public keys access tokens are signed with, so other
+// services can verify them. HMAC keys are never published.
+func GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(middleware.JWKS())
+}
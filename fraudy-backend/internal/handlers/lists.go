@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+const maxListImportSize = 10 << 20
+
+type AddressListRequest struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // blocklist, allowlist
+	Description string `json:"description"`
+}
+
+type AddressListEntryRequest struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+var listEntrySorts = map[string]sortField[models.AddressListEntry]{
+	"address": {"address", func(e models.AddressListEntry) interface{} { return e.Address }},
+	"id":      {"id", func(e models.AddressListEntry) interface{} { return e.ID }},
+}
+
+// findAddressList loads the list named in the URL if it belongs to the
+// organization, answering 404 otherwise.
+func findAddressList(w http.ResponseWriter, r *http.Request, organizationID uint) (models.AddressList, bool) {
+	var list models.AddressList
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], organizationID).First(&list)
+	if result.Error != nil {
+		http.Error(w, "List not found or unauthorized", http.StatusNotFound)
+		return list, false
+	}
+	return list, true
+}
+
+func parseAddressListRequest(w http.ResponseWriter, r *http.Request) (AddressListRequest, bool) {
+	var req AddressListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Kind = strings.ToLower(req.Kind)
+	if req.Kind == "" {
+		req.Kind = models.ListKindBlocklist
+	}
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Kind != models.ListKindBlocklist && req.Kind != models.ListKindAllowlist {
+		http.Error(w, "Kind must be blocklist or allowlist", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+func GetAddressLists(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var lists []models.AddressList
+	query := database.DB.Where("organization_id = ?", scope.OrganizationID)
+	if kind := r.URL.Query().Get("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if err := query.Order("name").Find(&lists).Error; err != nil {
+		http.Error(w, "Error fetching lists", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lists)
+}
+
+func CreateAddressList(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	req, valid := parseAddressListRequest(w, r)
+	if !valid {
+		return
+	}
+
+	list := models.AddressList{UserID: scope.UserID, OrganizationID: scope.OrganizationID, Name: req.Name, Kind: req.Kind, Description: req.Description}
+	if err := database.DB.Create(&list).Error; err != nil {
+		http.Error(w, "Error saving list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(list)
+}
+
+func UpdateAddressList(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+	req, valid := parseAddressListRequest(w, r)
+	if !valid {
+		return
+	}
+
+	list.Name = req.Name
+	list.Kind = req.Kind
+	list.Description = req.Description
+	if err := database.DB.Save(&list).Error; err != nil {
+		http.Error(w, "Error saving list", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// DeleteAddressList removes a list and its entries. Lists still referenced by
+// an alert rule cannot be deleted.
+func DeleteAddressList(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	var alerts int64
+	database.DB.Model(&models.Alert{}).Where("list_id = ?", list.ID).Count(&alerts)
+	if alerts > 0 {
+		http.Error(w, "List is used by an alert rule", http.StatusConflict)
+		return
+	}
+
+	database.DB.Where("list_id = ?", list.ID).Delete(&models.AddressListEntry{})
+	database.DB.Delete(&list)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "List deleted successfully"})
+}
+
+// GetAddressListEntries pages through a list's entries, optionally filtered
+// by an address prefix in "q".
+func GetAddressListEntries(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	query := database.DB.Model(&models.AddressListEntry{}).Where("list_id = ?", list.ID)
+	if q := r.URL.Query().Get("q"); q != "" {
+		query = query.Where("address LIKE ?", services.NormalizeListAddress(q)+"%")
+	}
+
+	entries, err := paginate(w, r, query, listEntrySorts, "address", func(e models.AddressListEntry) uint { return e.ID })
+	if err != nil {
+		writeListError(w, err, "Error fetching list entries")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+func AddAddressListEntry(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	var req AddressListEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	entry := models.AddressListEntry{Address: services.NormalizeListAddress(req.Address), Label: strings.TrimSpace(req.Label)}
+	if entry.Address == "" {
+		http.Error(w, "Address is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := services.ImportListEntries(list.ID, []models.AddressListEntry{entry}, false); err != nil {
+		http.Error(w, "Error saving list entry", http.StatusInternalServerError)
+		return
+	}
+	database.DB.Where("list_id = ? AND address = ?", list.ID, entry.Address).First(&entry)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+func DeleteAddressListEntry(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND list_id = ?", mux.Vars(r)["entryID"], list.ID).Delete(&models.AddressListEntry{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		http.Error(w, "List entry not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "List entry deleted successfully"})
+}
+
+// ImportAddressList loads entries from a CSV or JSON file, sent either as the
+// "file" field of a multipart form or as the raw request body. The format is
+// taken from ?format=, the file extension or the Content-Type, in that order.
+// With ?replace=true the list is replaced instead of extended.
+func ImportAddressList(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageLists)
+	if !ok {
+		return
+	}
+
+	list, found := findAddressList(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxListImportSize)
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	body := r.Body
+	contentType := r.Header.Get("Content-Type")
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "A file field is required", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+		}
+		contentType = header.Header.Get("Content-Type")
+	}
+	if format == "" {
+		switch {
+		case strings.Contains(contentType, "json"):
+			format = "json"
+		case strings.Contains(contentType, "csv"), strings.HasPrefix(contentType, "text/plain"):
+			format = "csv"
+		}
+	}
+
+	entries, err := services.ParseListEntries(format, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	replace := r.URL.Query().Get("replace") == "true"
+	if err := services.ImportListEntries(list.ID, entries, replace); err != nil {
+		http.Error(w, "Error importing list entries", http.StatusInternalServerError)
+		return
+	}
+
+	var total int64
+	database.DB.Model(&models.AddressListEntry{}).Where("list_id = ?", list.ID).Count(&total)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imported": len(entries),
+		"total":    total,
+	})
+}
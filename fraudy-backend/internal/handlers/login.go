@@ -1,79 +1,81 @@
-package handlers
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"os"
-	"time"
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-
-	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/crypto/bcrypt"
-)
-
-type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
-type LoginResponse struct {
-	Message string `json:"message"`
-	Token   string `json:"token,omitempty"`
-}
-
-func LoginUser(w http.ResponseWriter, r *http.Request) {
-	var req LoginRequest
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
-		return
-	}
-
-	// Find the user by email
-	var user models.User
-	result := database.DB.Where("email = ?", req.Email).First(&user)
-	if result.Error != nil {
-		http.Error(w, "User not found", http.StatusUnauthorized)
-		return
-	}
-
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
-		return
-	}
-
-	// Load JWT secret key
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		http.Error(w, "JWT_SECRET is not set", http.StatusInternalServerError)
-		return
-	}
-
-	fmt.Println("🔑 Signing JWT with Secret:", jwtSecret) // Debugging
-
-	// Create claims
-	claims := jwt.MapClaims{
-		"authorized": true,
-		"user_id":    user.ID,
-		"exp":        time.Now().Add(time.Hour * 72).Unix(), // 72 hours expiration
-	}
-
-	// Generate token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		http.Error(w, "Error generating token", http.StatusInternalServerError)
-		return
-	}
-
-	response := LoginResponse{
-		Message: "Login successful",
-		Token:   tokenString,
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type LoginResponse struct {
+	Message      string     `json:"message"`
+	Token        string     `json:"token,omitempty"` // access token
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+
+	// Set instead of the tokens when the account uses two-factor
+	// authentication; exchange the challenge at /login/2fa.
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+func LoginUser(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	// Find the user by email
+	var user models.User
+	result := database.DB.Where("email = ?", req.Email).First(&user)
+	if result.Error != nil {
+		http.Error(w, "User not found", http.StatusUnauthorized)
+		return
+	}
+
+	// Verify password
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		return
+	}
+
+	completeLogin(w, r, user)
+}
+
+// completeLogin signs in a user who proved their first factor, or hands out
+// a two-factor challenge if their account requires one.
+func completeLogin(w http.ResponseWriter, r *http.Request, user models.User) {
+	if user.EmailVerifiedAt == nil && emailVerificationRequired() {
+		http.Error(w, "Email address not verified", http.StatusForbidden)
+		return
+	}
+
+	if user.TOTPEnabledAt != nil {
+		challenge, expiresAt, err := services.IssueLoginChallenge(int(user.ID))
+		if err != nil {
+			http.Error(w, "Error starting two-factor login", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(LoginResponse{
+			Message:           "Two-factor authentication required",
+			ExpiresAt:         &expiresAt,
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		})
+		return
+	}
+
+	issueSession(w, r, user, "Login successful")
+}
@@ -1,108 +1,351 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-	"github.com/gorilla/mux"
-)
-
-type NotificationConfigRequest struct {
-	ConfigName      string   `json:"config_name"`
-	NotificationType string   `json:"notification_type"` // slack, email, telegram, discord
-	SlackWebhook    string   `json:"slack_webhook,omitempty"`
-	EmailSender     string   `json:"email_sender,omitempty"`
-	EmailPassword   string   `json:"email_password,omitempty"`
-	SMTPServer      string   `json:"smtp_server,omitempty"`
-	SMTPPort        string   `json:"smtp_port,omitempty"`
-	RecipientEmails []string `json:"recipient_emails,omitempty"`
-	TelegramChatID  string   `json:"telegram_chat_id,omitempty"`
-	DiscordChannel  string   `json:"discord_channel,omitempty"`
-}
-
-func CreateNotificationConfig(w http.ResponseWriter, r *http.Request) {
-	userID, ok := r.Context().Value("user_id").(int)
-	if !ok {
-		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
-		return
-	}
-
-	var req NotificationConfigRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
-		return
-	}
-	// Convert recipient emails to JSON string
-	recipientEmailsJSON, err := json.Marshal(req.RecipientEmails)
-	if err != nil {
-		http.Error(w, "Error encoding recipient emails", http.StatusInternalServerError)
-		return
-	}
-
-	config := models.NotificationConfig{
-		UserID:          userID,
-		ConfigName:      req.ConfigName,
-		NotificationType: req.NotificationType,
-		SlackWebhook:    req.SlackWebhook,
-		EmailSender:     req.EmailSender,
-		EmailPassword:   req.EmailPassword,
-		SMTPServer:      req.SMTPServer,
-		SMTPPort:        req.SMTPPort,
-		RecipientEmails: string(recipientEmailsJSON),
-		TelegramChatID:  req.TelegramChatID,
-		DiscordChannel:  req.DiscordChannel,
-	}
-
-	result := database.DB.Create(&config)
-	if result.Error != nil {
-		http.Error(w, "Error saving notification config", http.StatusInternalServerError)
-		return
-	}
-
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Notification configuration saved successfully"})
-}
-
-func GetUserNotificationConfigs(w http.ResponseWriter, r *http.Request) {
-	userID, ok := r.Context().Value("user_id").(int)
-	if !ok {
-		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
-		return
-	}
-
-	var configs []models.NotificationConfig
-	result := database.DB.Where("user_id = ?", userID).Find(&configs)
-
-	if result.Error != nil {
-		http.Error(w, "Error fetching configurations", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(configs)
-}
-
-func DeleteNotificationConfig(w http.ResponseWriter, r *http.Request) {
-	userID, ok := r.Context().Value("user_id").(int)
-	if !ok {
-		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
-		return
-	}
-
-	vars := mux.Vars(r)
-	configID := vars["id"]
-
-	var config models.NotificationConfig
-	result := database.DB.Where("id = ? AND user_id = ?", configID, userID).First(&config)
-	if result.Error != nil {
-		http.Error(w, "Configuration not found or unauthorized", http.StatusNotFound)
-		return
-	}
-
-	// Delete the configuration
-	database.DB.Delete(&config)
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Notification configuration deleted successfully"})
-}
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type NotificationConfigRequest struct {
+	ConfigName      string   `json:"config_name"`
+	NotificationType string   `json:"notification_type"` // slack, email, telegram, discord
+	SlackWebhook    string   `json:"slack_webhook,omitempty"`
+	EmailSender     string   `json:"email_sender,omitempty"`
+	EmailPassword   string   `json:"email_password,omitempty"`
+	SMTPServer      string   `json:"smtp_server,omitempty"`
+	SMTPPort        string   `json:"smtp_port,omitempty"`
+	RecipientEmails []string `json:"recipient_emails,omitempty"`
+	TelegramChatID  string   `json:"telegram_chat_id,omitempty"`
+	DiscordChannel  string   `json:"discord_channel,omitempty"`
+}
+
+type NotificationTestResponse struct {
+	Success        bool       `json:"success"`
+	Channel        string     `json:"channel"`
+	Message        string     `json:"message"`
+	Error          string     `json:"error,omitempty"`
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+}
+
+const redactedSecret = "••••"
+
+// redactNotificationSecrets hides secret fields before a config is returned to
+// the client. Secrets are write-only through the API.
+func redactNotificationSecrets(config *models.NotificationConfig) {
+	if config.EmailPassword != "" {
+		config.EmailPassword = redactedSecret
+	}
+	if config.SlackWebhook != "" {
+		config.SlackWebhook = redactedSecret
+	}
+	if config.DiscordChannel != "" {
+		config.DiscordChannel = redactedSecret
+	}
+}
+
+// secretProvided reports whether a secret was sent in the request or is
+// already stored. Echoing back the redacted placeholder counts as omitting it.
+func secretProvided(value string, stored string) bool {
+	return (value != "" && value != redactedSecret) || stored != ""
+}
+
+// validateNotificationConfig checks the fields each NotificationType needs.
+// existing is the stored config on update and nil on create.
+func validateNotificationConfig(req NotificationConfigRequest, existing *models.NotificationConfig) error {
+	var storedPassword, storedWebhook, storedDiscord string
+	if existing != nil {
+		storedPassword, storedWebhook, storedDiscord = existing.EmailPassword, existing.SlackWebhook, existing.DiscordChannel
+	}
+
+	if strings.TrimSpace(req.ConfigName) == "" {
+		return fmt.Errorf("config_name is required")
+	}
+
+	switch req.NotificationType {
+	case "email":
+		if _, err := mail.ParseAddress(req.EmailSender); err != nil {
+			return fmt.Errorf("email_sender must be a valid email address")
+		}
+		if !secretProvided(req.EmailPassword, storedPassword) {
+			return fmt.Errorf("email_password is required")
+		}
+		if req.SMTPServer == "" {
+			return fmt.Errorf("smtp_server is required")
+		}
+		port, err := strconv.Atoi(req.SMTPPort)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("smtp_port must be a number between 1 and 65535")
+		}
+		if len(req.RecipientEmails) == 0 {
+			return fmt.Errorf("recipient_emails must contain at least one address")
+		}
+		for _, recipient := range req.RecipientEmails {
+			if _, err := mail.ParseAddress(recipient); err != nil {
+				return fmt.Errorf("recipient_emails contains an invalid address: %s", recipient)
+			}
+		}
+	case "slack":
+		if !secretProvided(req.SlackWebhook, storedWebhook) {
+			return fmt.Errorf("slack_webhook is required")
+		}
+		if req.SlackWebhook != "" && req.SlackWebhook != redactedSecret {
+			if err := services.ValidateWebhookURL("slack", req.SlackWebhook); err != nil {
+				return fmt.Errorf("slack_webhook %v", err)
+			}
+		}
+	case "telegram":
+		if req.TelegramChatID == "" {
+			return fmt.Errorf("telegram_chat_id is required")
+		}
+	case "discord":
+		if !secretProvided(req.DiscordChannel, storedDiscord) {
+			return fmt.Errorf("discord_channel is required")
+		}
+		if req.DiscordChannel != "" && req.DiscordChannel != redactedSecret {
+			if err := services.ValidateWebhookURL("discord", req.DiscordChannel); err != nil {
+				return fmt.Errorf("discord_channel %v", err)
+			}
+		}
+	default:
+		return fmt.Errorf("notification_type must be one of slack, email, telegram, discord")
+	}
+	return nil
+}
+
+func CreateNotificationConfig(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	var req NotificationConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := validateNotificationConfig(req, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Convert recipient emails to JSON string
+	recipientEmailsJSON, err := json.Marshal(req.RecipientEmails)
+	if err != nil {
+		http.Error(w, "Error encoding recipient emails", http.StatusInternalServerError)
+		return
+	}
+
+	config := models.NotificationConfig{
+		UserID:          scope.UserID,
+		OrganizationID:  scope.OrganizationID,
+		ConfigName:      req.ConfigName,
+		NotificationType: req.NotificationType,
+		SlackWebhook:    req.SlackWebhook,
+		EmailSender:     req.EmailSender,
+		EmailPassword:   req.EmailPassword,
+		SMTPServer:      req.SMTPServer,
+		SMTPPort:        req.SMTPPort,
+		RecipientEmails: string(recipientEmailsJSON),
+		TelegramChatID:  req.TelegramChatID,
+		DiscordChannel:  req.DiscordChannel,
+	}
+	if err := services.EncryptNotificationSecrets(&config); err != nil {
+		http.Error(w, "Error encrypting notification secrets", http.StatusInternalServerError)
+		return
+	}
+
+	result := database.DB.Create(&config)
+	if result.Error != nil {
+		http.Error(w, "Error saving notification config", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification configuration saved successfully"})
+}
+
+func GetUserNotificationConfigs(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var configs []models.NotificationConfig
+	result := database.DB.Where("organization_id = ?", scope.OrganizationID).Find(&configs)
+
+	if result.Error != nil {
+		http.Error(w, "Error fetching configurations", http.StatusInternalServerError)
+		return
+	}
+	for i := range configs {
+		redactNotificationSecrets(&configs[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(configs)
+}
+
+// UpdateNotificationConfig replaces a config's fields. Secret fields that are
+// omitted or sent back redacted keep their stored value.
+func UpdateNotificationConfig(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	var config models.NotificationConfig
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], scope.OrganizationID).First(&config)
+	if result.Error != nil {
+		http.Error(w, "Configuration not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	var req NotificationConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if err := validateNotificationConfig(req, &config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recipientEmailsJSON, err := json.Marshal(req.RecipientEmails)
+	if err != nil {
+		http.Error(w, "Error encoding recipient emails", http.StatusInternalServerError)
+		return
+	}
+
+	// Only newly supplied secrets are encrypted; stored ones stay as they are.
+	secrets := models.NotificationConfig{}
+	if req.EmailPassword != "" && req.EmailPassword != redactedSecret {
+		secrets.EmailPassword = req.EmailPassword
+	}
+	if req.SlackWebhook != "" && req.SlackWebhook != redactedSecret {
+		secrets.SlackWebhook = req.SlackWebhook
+	}
+	if req.DiscordChannel != "" && req.DiscordChannel != redactedSecret {
+		secrets.DiscordChannel = req.DiscordChannel
+	}
+	if err := services.EncryptNotificationSecrets(&secrets); err != nil {
+		http.Error(w, "Error encrypting notification secrets", http.StatusInternalServerError)
+		return
+	}
+	deliveryChanged := config.NotificationType != req.NotificationType ||
+		config.EmailSender != req.EmailSender ||
+		config.SMTPServer != req.SMTPServer ||
+		config.SMTPPort != req.SMTPPort ||
+		config.RecipientEmails != string(recipientEmailsJSON) ||
+		config.TelegramChatID != req.TelegramChatID
+	if secrets.EmailPassword != "" {
+		config.EmailPassword = secrets.EmailPassword
+		deliveryChanged = true
+	}
+	if secrets.SlackWebhook != "" {
+		config.SlackWebhook = secrets.SlackWebhook
+		deliveryChanged = true
+	}
+	if secrets.DiscordChannel != "" {
+		config.DiscordChannel = secrets.DiscordChannel
+		deliveryChanged = true
+	}
+
+	config.ConfigName = req.ConfigName
+	config.NotificationType = req.NotificationType
+	config.EmailSender = req.EmailSender
+	config.SMTPServer = req.SMTPServer
+	config.SMTPPort = req.SMTPPort
+	config.RecipientEmails = string(recipientEmailsJSON)
+	config.TelegramChatID = req.TelegramChatID
+	if deliveryChanged {
+		// An earlier successful test says nothing about the new settings.
+		config.LastVerifiedAt = nil
+	}
+
+	if err := database.DB.Save(&config).Error; err != nil {
+		http.Error(w, "Error updating notification config", http.StatusInternalServerError)
+		return
+	}
+
+	redactNotificationSecrets(&config)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(config)
+}
+
+func DeleteNotificationConfig(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	configID := vars["id"]
+
+	var config models.NotificationConfig
+	result := database.DB.Where("id = ? AND organization_id = ?", configID, scope.OrganizationID).First(&config)
+	if result.Error != nil {
+		http.Error(w, "Configuration not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	// Delete the configuration
+	database.DB.Delete(&config)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification configuration deleted successfully"})
+}
+
+// TestNotificationConfig sends a synthetic alert through the config's channel
+// and reports the delivery outcome, including the SMTP or webhook error.
+func TestNotificationConfig(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	var config models.NotificationConfig
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], scope.OrganizationID).First(&config)
+	if result.Error != nil {
+		http.Error(w, "Configuration not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	response := NotificationTestResponse{
+		Channel:        config.NotificationType,
+		LastVerifiedAt: config.LastVerifiedAt,
+	}
+	status := http.StatusOK
+
+	rendered, err := services.RenderNotification(scope.OrganizationID, config.NotificationType, services.EventAlert, sampleNotificationData(scope))
+	if err == nil {
+		err = services.DecryptNotificationSecrets(&config)
+	}
+	if err == nil {
+		rendered.Subject = "[Test] " + rendered.Subject
+		err = services.DeliverNotification(config, rendered)
+	}
+
+	if err != nil {
+		status = http.StatusBadGateway
+		response.Message = "Test notification could not be delivered"
+		response.Error = err.Error()
+	} else {
+		now := time.Now()
+		database.DB.Model(&config).Update("last_verified_at", now)
+		response.Success = true
+		response.Message = "Test notification delivered successfully"
+		response.LastVerifiedAt = &now
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
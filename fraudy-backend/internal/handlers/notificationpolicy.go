@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"github.com/gorilla/mux"
+)
+
+type NotificationPolicyRequest struct {
+	Channel           string `json:"channel"` // slack, email, telegram, discord
+	SuppressionWindow int    `json:"suppression_window"`
+	MaxPerHour        int    `json:"max_per_hour"`
+	DigestMode        bool   `json:"digest_mode"`
+	DigestInterval    int    `json:"digest_interval"`
+}
+
+func GetAlertNotificationPolicies(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var alert models.Alert
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], scope.OrganizationID).First(&alert)
+	if result.Error != nil {
+		http.Error(w, "Alert not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	var policies []models.NotificationPolicy
+	if err := database.DB.Where("alert_id = ?", alert.ID).Find(&policies).Error; err != nil {
+		http.Error(w, "Error fetching notification policies", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+func UpsertAlertNotificationPolicy(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageAlerts)
+	if !ok {
+		return
+	}
+
+	var alert models.Alert
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], scope.OrganizationID).First(&alert)
+	if result.Error != nil {
+		http.Error(w, "Alert not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	var req NotificationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	req.Channel = strings.ToLower(req.Channel)
+	if req.Channel == "" || req.SuppressionWindow < 0 || req.MaxPerHour < 0 || req.DigestInterval < 0 {
+		http.Error(w, "Channel is required and limits must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	var policy models.NotificationPolicy
+	database.DB.Where("alert_id = ? AND channel = ?", alert.ID, req.Channel).First(&policy)
+	policy.AlertID = alert.ID
+	policy.Channel = req.Channel
+	policy.SuppressionWindow = req.SuppressionWindow
+	policy.MaxPerHour = req.MaxPerHour
+	policy.DigestMode = req.DigestMode
+	policy.DigestInterval = req.DigestInterval
+
+	if err := database.DB.Save(&policy).Error; err != nil {
+		http.Error(w, "Error saving notification policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
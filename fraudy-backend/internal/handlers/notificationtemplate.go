@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type NotificationTemplateRequest struct {
+	Channel   string `json:"channel"`    // email, slack, discord
+	EventType string `json:"event_type"` // alert, digest
+	Source    string `json:"source"`
+}
+
+type NotificationTemplatePreviewResponse struct {
+	Source  string `json:"source"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// sampleNotificationData is what previews and template validation render with.
+func sampleNotificationData(scope requestScope) services.NotificationData {
+	alert := models.Alert{
+		UserID:         scope.UserID,
+		OrganizationID: scope.OrganizationID,
+		AlertName: "Sample High Failure Rate Alert",
+		RuleType:  "highFailureRate",
+		WalletID:  "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ",
+		Flag:      "Medium",
+	}
+	activity := models.FraudActivity{
+		Account:           alert.WalletID,
+		Type:              alert.RuleType,
+		TransactionHash:   "3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889",
+		TransactionHashes: `["3389e9f0f1a65f19736cacf544c2e825313e8447f569233bb8db39aa607c8889","b9d0b2292c4e09e8eb22d036171491e87b8d2086bf8b265874c8d182cb9c9020"]`,
+		FailureCount:      10,
+		Flag:              "Medium",
+	}
+	activity.CreatedAt = time.Now()
+	data := services.NewNotificationData(alert, activity)
+	data.Report = &services.ReportSummary{
+		Name:          "Weekly Fraud Summary",
+		From:          activity.CreatedAt.AddDate(0, 0, -7),
+		To:            activity.CreatedAt,
+		NewActivities: 3,
+		ByRule:        []services.ReportCount{{Key: "highFailureRate", Count: 2}, {Key: "listPayment", Count: 1}},
+		ByFlag:        []services.ReportCount{{Key: "Medium", Count: 3}},
+		TopAccounts:   []services.ReportCount{{Key: alert.WalletID, Count: 3}},
+		OpenCases:     2,
+	}
+	return data
+}
+
+func parseTemplateRequest(r *http.Request) (NotificationTemplateRequest, bool) {
+	var req NotificationTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, false
+	}
+	req.Channel = strings.ToLower(req.Channel)
+	req.EventType = strings.ToLower(req.EventType)
+	if req.EventType == "" {
+		req.EventType = services.EventAlert
+	}
+	return req, req.Channel != ""
+}
+
+func GetNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var templates []models.NotificationTemplate
+	if err := database.DB.Where("organization_id = ?", scope.OrganizationID).Find(&templates).Error; err != nil {
+		http.Error(w, "Error fetching notification templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+func SaveNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	req, valid := parseTemplateRequest(r)
+	if !valid || req.Source == "" {
+		http.Error(w, "Channel and source are required", http.StatusBadRequest)
+		return
+	}
+
+	// Reject templates that would fail at delivery time.
+	if _, err := services.RenderTemplateSource(req.Channel, req.Source, sampleNotificationData(scope)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var tmpl models.NotificationTemplate
+	database.DB.Where("organization_id = ? AND channel = ? AND event_type = ?", scope.OrganizationID, req.Channel, req.EventType).First(&tmpl)
+	tmpl.UserID = scope.UserID
+	tmpl.OrganizationID = scope.OrganizationID
+	tmpl.Channel = req.Channel
+	tmpl.EventType = req.EventType
+	tmpl.Source = req.Source
+
+	if err := database.DB.Save(&tmpl).Error; err != nil {
+		http.Error(w, "Error saving notification template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tmpl)
+}
+
+func DeleteNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	var tmpl models.NotificationTemplate
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], scope.OrganizationID).First(&tmpl)
+	if result.Error != nil {
+		http.Error(w, "Template not found or unauthorized", http.StatusNotFound)
+		return
+	}
+
+	// Hard delete so the unique index allows a new override later.
+	database.DB.Unscoped().Delete(&tmpl)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification template deleted successfully"})
+}
+
+// PreviewNotificationTemplate renders either the submitted source or the
+// template currently in effect for the organization against sample alert data.
+func PreviewNotificationTemplate(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	req, valid := parseTemplateRequest(r)
+	if !valid {
+		http.Error(w, "Channel is required", http.StatusBadRequest)
+		return
+	}
+
+	data := sampleNotificationData(scope)
+	if req.EventType == services.EventDigest {
+		data = services.NewNotificationData(data.Alert, data.Activity, data.Activity)
+	}
+
+	source := req.Source
+	if source == "" {
+		var tmpl models.NotificationTemplate
+		result := database.DB.Where("organization_id = ? AND channel = ? AND event_type = ?", scope.OrganizationID, req.Channel, req.EventType).First(&tmpl)
+		if result.Error == nil {
+			source = tmpl.Source
+		} else {
+			defaultSource, err := services.DefaultTemplateSource(req.Channel, req.EventType)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			source = defaultSource
+		}
+	}
+
+	rendered, err := services.RenderTemplateSource(req.Channel, source, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(NotificationTemplatePreviewResponse{
+		Source:  source,
+		Subject: rendered.Subject,
+		Body:    rendered.Body,
+	})
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type OrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+type MemberRequest struct {
+	Email string `json:"email"` // only when adding a member
+	Role  string `json:"role"`
+}
+
+// UserOrganization is an organization the caller belongs to. Its ID goes in
+// the X-Organization-ID header to act in it.
+type UserOrganization struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type OrganizationMember struct {
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GetUserOrganizations lists the organizations the caller belongs to and
+// their role in each.
+func GetUserOrganizations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	organizations := []UserOrganization{}
+	err := database.DB.Model(&models.Membership{}).
+		Select("organizations.id, organizations.name, memberships.role").
+		Joins("JOIN organizations ON organizations.id = memberships.organization_id AND organizations.deleted_at IS NULL").
+		Where("memberships.user_id = ?", userID).
+		Order("memberships.id ASC").Scan(&organizations).Error
+	if err != nil {
+		http.Error(w, "Error fetching organizations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(organizations)
+}
+
+// CreateOrganization creates an organization owned by the caller.
+func CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	var req OrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	var organization models.Organization
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		organization, err = services.CreateOrganization(tx, strings.TrimSpace(req.Name), userID)
+		return err
+	})
+	if err != nil {
+		http.Error(w, "Error creating organization", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(UserOrganization{ID: organization.ID, Name: organization.Name, Role: models.RoleOwner})
+}
+
+// GetCurrentOrganization returns the organization the request acts in.
+func GetCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var organization models.Organization
+	if err := database.DB.First(&organization, scope.OrganizationID).Error; err != nil {
+		http.Error(w, "Organization not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserOrganization{ID: organization.ID, Name: organization.Name, Role: scope.Role})
+}
+
+func UpdateCurrentOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageOrganization)
+	if !ok {
+		return
+	}
+
+	var req OrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	err := database.DB.Model(&models.Organization{}).Where("id = ?", scope.OrganizationID).Update("name", name).Error
+	if err != nil {
+		http.Error(w, "Error updating organization", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserOrganization{ID: scope.OrganizationID, Name: name, Role: scope.Role})
+}
+
+func GetOrganizationMembers(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	members := []OrganizationMember{}
+	err := database.DB.Model(&models.Membership{}).
+		Select("memberships.user_id, users.username, users.email, memberships.role, memberships.created_at AS joined_at").
+		Joins("JOIN users ON users.id = memberships.user_id AND users.deleted_at IS NULL").
+		Where("memberships.organization_id = ?", scope.OrganizationID).
+		Order("memberships.created_at ASC").Scan(&members).Error
+	if err != nil {
+		http.Error(w, "Error fetching members", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
+
+// checkGrantableRole answers 400 or 403 unless the caller may hand out role.
+// Nobody can grant a role above their own.
+func checkGrantableRole(w http.ResponseWriter, scope requestScope, role string) bool {
+	if !access.ValidRole(role) {
+		http.Error(w, "Role must be owner, admin, analyst or viewer", http.StatusBadRequest)
+		return false
+	}
+	if !access.AtLeast(scope.Role, role) {
+		http.Error(w, fmt.Sprintf("Forbidden: Only an owner can grant the %s role", role), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// AddOrganizationMember adds an existing user, found by email, to the
+// organization.
+func AddOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageMembers)
+	if !ok {
+		return
+	}
+
+	var req MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+	if req.Role == "" {
+		req.Role = models.RoleViewer
+	}
+	if !checkGrantableRole(w, scope, req.Role) {
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", strings.TrimSpace(req.Email)).First(&user).Error; err != nil {
+		http.Error(w, "No user with this email", http.StatusNotFound)
+		return
+	}
+	if _, err := services.FindMembership(int(user.ID), scope.OrganizationID); err == nil {
+		http.Error(w, "User is already a member", http.StatusConflict)
+		return
+	}
+
+	membership := models.Membership{OrganizationID: scope.OrganizationID, UserID: int(user.ID), Role: req.Role}
+	if err := database.DB.Create(&membership).Error; err != nil {
+		http.Error(w, "Error adding member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(OrganizationMember{
+		UserID:   membership.UserID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     membership.Role,
+		JoinedAt: membership.CreatedAt,
+	})
+}
+
+// findMember loads the membership of the user in the URL, answering 404 when
+// they are not in the caller's organization and 403 when they outrank the
+// caller.
+func findMember(w http.ResponseWriter, r *http.Request, scope requestScope) (models.Membership, bool) {
+	userID, err := strconv.Atoi(mux.Vars(r)["userID"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return models.Membership{}, false
+	}
+	membership, err := services.FindMembership(userID, scope.OrganizationID)
+	if err != nil {
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return membership, false
+	}
+	if !access.AtLeast(scope.Role, membership.Role) {
+		http.Error(w, "Forbidden: Only an owner can change another owner", http.StatusForbidden)
+		return membership, false
+	}
+	return membership, true
+}
+
+func writeMemberError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, services.ErrLastOwner) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+func UpdateOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageMembers)
+	if !ok {
+		return
+	}
+
+	var req MemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if !checkGrantableRole(w, scope, req.Role) {
+		return
+	}
+	membership, found := findMember(w, r, scope)
+	if !found {
+		return
+	}
+
+	if err := services.ChangeMemberRole(&membership, req.Role); err != nil {
+		writeMemberError(w, err, "Error updating member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(membership)
+}
+
+// RemoveOrganizationMember removes a member. Any member may remove themselves
+// to leave the organization; removing others takes ManageMembers.
+func RemoveOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	permission := access.ManageMembers
+	if userID, ok := r.Context().Value("user_id").(int); ok && mux.Vars(r)["userID"] == strconv.Itoa(userID) {
+		permission = access.ViewData
+	}
+	scope, ok := authorize(w, r, permission)
+	if !ok {
+		return
+	}
+
+	membership, found := findMember(w, r, scope)
+	if !found {
+		return
+	}
+	if err := services.RemoveMember(membership); err != nil {
+		writeMemberError(w, err, "Error removing member")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Member removed successfully"})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listParamError reports an invalid sort, limit or cursor parameter.
+type listParamError string
+
+func (e listParamError) Error() string {
+	return string(e)
+}
+
+// writeListError answers with 400 for bad list parameters and 500 otherwise.
+func writeListError(w http.ResponseWriter, err error, message string) {
+	if paramErr, ok := err.(listParamError); ok {
+		http.Error(w, paramErr.Error(), http.StatusBadRequest)
+		return
+	}
+	fmt.Println("❌ "+message+":", err)
+	http.Error(w, message, http.StatusInternalServerError)
+}
+
+// sortField maps a public sort name to its column and to the value of that
+// column on a row, which becomes part of the next page's cursor.
+type sortField[T any] struct {
+	column string
+	value  func(T) interface{}
+}
+
+// pageCursor marks the last row of a page by its sort value and ID so the next
+// page can continue from it even when rows are inserted in between.
+type pageCursor struct {
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(raw, &cursor)
+	return cursor, err
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain YYYY-MM-DD dates.
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// applyDateRange filters column by the "from" and "to" query parameters.
+// A plain date in "to" includes the whole day.
+func applyDateRange(query *gorm.DB, r *http.Request, column string) (*gorm.DB, error) {
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := parseTimeParam(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date: %s", from)
+		}
+		query = query.Where(column+" >= ?", t)
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := parseTimeParam(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date: %s", to)
+		}
+		if !strings.Contains(to, "T") {
+			t = t.Add(24 * time.Hour)
+		}
+		query = query.Where(column+" < ?", t)
+	}
+	return query, nil
+}
+
+// paginate applies the "sort", "cursor" and "limit" query parameters to query
+// and returns one page of rows. It sets X-Total-Count to the number of rows
+// matching query and X-Next-Cursor when another page is available.
+//
+// sort takes a field name from sorts, prefixed with "-" for descending order.
+func paginate[T any](w http.ResponseWriter, r *http.Request, query *gorm.DB, sorts map[string]sortField[T], defaultSort string, idOf func(T) uint) ([]T, error) {
+	params := r.URL.Query()
+
+	sortParam := params.Get("sort")
+	if sortParam == "" {
+		sortParam = defaultSort
+	}
+	descending := strings.HasPrefix(sortParam, "-")
+	field, ok := sorts[strings.TrimPrefix(sortParam, "-")]
+	if !ok {
+		return nil, listParamError("unsupported sort field: " + strings.TrimPrefix(sortParam, "-"))
+	}
+
+	limit := defaultPageSize
+	if value := params.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return nil, listParamError("limit must be a positive number")
+		}
+		limit = min(parsed, maxPageSize)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	page := query.Session(&gorm.Session{})
+	if encoded := params.Get("cursor"); encoded != "" {
+		cursor, err := decodeCursor(encoded)
+		if err != nil {
+			return nil, listParamError("invalid cursor")
+		}
+		page = page.Where(fmt.Sprintf("(%s, id) %s (?, ?)", field.column, comparison), cursor.Value, cursor.ID)
+	}
+
+	var rows []T
+	err := page.Order(fmt.Sprintf("%s %s, id %s", field.column, direction, direction)).Limit(limit + 1).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		w.Header().Set("X-Next-Cursor", encodeCursor(pageCursor{Value: field.value(last), ID: idOf(last)}))
+	}
+	return rows, nil
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterUserRejectsShortPasswords(t *testing.T) {
+	body := `{"username": "ada", "email": "ada@example.com", "password": "1234567"}`
+	w := httptest.NewRecorder()
+	RegisterUser(w, httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Password must be at least 8 characters")
+}
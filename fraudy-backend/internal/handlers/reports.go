@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/stellar/go/strkey"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReportRequest struct {
+	Address  string `json:"address"`
+	Type     string `json:"type"` // wallet, phishing
+	Reason   string `json:"reason"`
+	Evidence string `json:"evidence"`
+}
+
+type ReportVoteRequest struct {
+	Value int `json:"value"` // 1 or -1
+}
+
+type ReportModerationRequest struct {
+	Status string `json:"status"` // confirmed, rejected
+	Note   string `json:"note"`
+}
+
+var reportSorts = map[string]sortField[models.ReportedAddress]{
+	"created_at": {"created_at", func(r models.ReportedAddress) interface{} { return r.CreatedAt }},
+	"address":    {"address", func(r models.ReportedAddress) interface{} { return r.Address }},
+	"upvotes":    {"upvotes", func(r models.ReportedAddress) interface{} { return r.Upvotes }},
+}
+
+// CreateReport files a community report. A user can have one pending report
+// per address at a time.
+func CreateReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ReportAddresses)
+	if !ok {
+		return
+	}
+
+	var req ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		http.Error(w, "A reason is required", http.StatusBadRequest)
+		return
+	}
+
+	address := services.NormalizeReportedAddress(req.Type, req.Address)
+	switch req.Type {
+	case models.ReportTypeWallet:
+		if !strkey.IsValidEd25519PublicKey(address) {
+			http.Error(w, "Address must be a valid Stellar account ID", http.StatusBadRequest)
+			return
+		}
+	case models.ReportTypePhishing:
+		if address == "" || !strings.Contains(address, ".") {
+			http.Error(w, "Address must be a domain or URL", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "Type must be wallet or phishing", http.StatusBadRequest)
+		return
+	}
+
+	var pending int64
+	err := database.DB.Model(&models.ReportedAddress{}).
+		Where("address = ? AND reported_by = ? AND status = ?", address, scope.UserID, models.ReportStatusPending).
+		Count(&pending).Error
+	if err != nil {
+		http.Error(w, "Error checking existing reports", http.StatusInternalServerError)
+		return
+	}
+	if pending > 0 {
+		http.Error(w, "You already have a pending report for this address", http.StatusConflict)
+		return
+	}
+
+	report := models.ReportedAddress{
+		Address:    address,
+		Type:       req.Type,
+		Reason:     req.Reason,
+		Evidence:   req.Evidence,
+		ReportedBy: scope.UserID,
+	}
+	if err := database.DB.Create(&report).Error; err != nil {
+		http.Error(w, "Error saving report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetReports lists community reports, filtered by address, type and status.
+func GetReports(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorize(w, r, access.ViewData); !ok {
+		return
+	}
+
+	params := r.URL.Query()
+	query := database.DB.Model(&models.ReportedAddress{})
+	if address := params.Get("address"); address != "" {
+		query = query.Where("address = ?", services.NormalizeReportedAddress(params.Get("type"), address))
+	}
+	if reportType := params.Get("type"); reportType != "" {
+		query = query.Where("type = ?", reportType)
+	}
+	if status := params.Get("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	reports, err := paginate(w, r, query, reportSorts, "-created_at", func(r models.ReportedAddress) uint { return r.ID })
+	if err != nil {
+		writeListError(w, err, "Error fetching reports")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// VoteOnReport records the caller's vote and refreshes the report's tallies.
+// Voting again replaces the caller's previous vote. Reporters cannot vote on
+// their own reports.
+func VoteOnReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ReportAddresses)
+	if !ok {
+		return
+	}
+
+	var req ReportVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.Value != 1 && req.Value != -1) {
+		http.Error(w, "Value must be 1 or -1", http.StatusBadRequest)
+		return
+	}
+
+	var report models.ReportedAddress
+	if err := database.DB.First(&report, mux.Vars(r)["id"]).Error; err != nil {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	}
+	if report.ReportedBy == scope.UserID {
+		http.Error(w, "You cannot vote on your own report", http.StatusForbidden)
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		vote := models.ReportVote{ReportID: report.ID, UserID: scope.UserID, Value: req.Value}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "report_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": req.Value, "deleted_at": nil}),
+		}).Create(&vote).Error
+		if err != nil {
+			return err
+		}
+
+		var upvotes, downvotes int64
+		tx.Model(&models.ReportVote{}).Where("report_id = ? AND value = 1", report.ID).Count(&upvotes)
+		tx.Model(&models.ReportVote{}).Where("report_id = ? AND value = -1", report.ID).Count(&downvotes)
+		report.Upvotes, report.Downvotes = int(upvotes), int(downvotes)
+		return tx.Model(&report).Updates(map[string]interface{}{"upvotes": upvotes, "downvotes": downvotes}).Error
+	})
+	if err != nil {
+		http.Error(w, "Error saving vote", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ModerateReport confirms or rejects a report. Only moderators may call it,
+// whatever their role in their organization.
+func ModerateReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var moderator models.User
+	if err := database.DB.First(&moderator, scope.UserID).Error; err != nil || !moderator.IsModerator {
+		http.Error(w, "Only moderators can moderate reports", http.StatusForbidden)
+		return
+	}
+
+	var req ReportModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil ||
+		(req.Status != models.ReportStatusConfirmed && req.Status != models.ReportStatusRejected && req.Status != models.ReportStatusPending) {
+		http.Error(w, "Status must be confirmed, rejected or pending", http.StatusBadRequest)
+		return
+	}
+
+	var report models.ReportedAddress
+	if err := database.DB.First(&report, mux.Vars(r)["id"]).Error; err != nil {
+		http.Error(w, "Report not found", http.StatusNotFound)
+		return
+	}
+
+	report.Status = req.Status
+	report.ModerationNote = req.Note
+	report.ModeratedBy = &scope.UserID
+	if err := database.DB.Save(&report).Error; err != nil {
+		http.Error(w, "Error saving moderation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetAddressReputation returns the aggregated community signal for an address.
+func GetAddressReputation(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorize(w, r, access.ViewData); !ok {
+		return
+	}
+
+	address := services.NormalizeReportedAddress(r.URL.Query().Get("type"), mux.Vars(r)["address"])
+	reputation, err := services.GetAddressReputation(address)
+	if err != nil {
+		http.Error(w, "Error fetching reputation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reputation)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/risk"
+	"github.com/gorilla/mux"
+	"github.com/stellar/go/strkey"
+)
+
+// GetAccountRisk answers "is this address safe to pay?" with a score and the
+// factors behind it. Pass ?network=public for mainnet accounts.
+func GetAccountRisk(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorize(w, r, access.ViewData); !ok {
+		return
+	}
+
+	account := mux.Vars(r)["account"]
+	if !strkey.IsValidEd25519PublicKey(account) {
+		http.Error(w, "Account must be a valid Stellar account ID", http.StatusBadRequest)
+		return
+	}
+	network := r.URL.Query().Get("network")
+	if network == "" {
+		network = "testnet"
+	}
+	if network != "testnet" && network != "public" {
+		http.Error(w, "Network must be testnet or public", http.StatusBadRequest)
+		return
+	}
+
+	assessment, err := risk.Assess(account, network)
+	if err != nil {
+		http.Error(w, "Error assessing account risk: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assessment)
+}
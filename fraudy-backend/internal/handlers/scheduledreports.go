@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type ScheduledReportRequest struct {
+	Name     string   `json:"name"`
+	Schedule string   `json:"schedule"` // e.g. "0 9 * * 1" or "@weekly"
+	Timezone string   `json:"timezone"`
+	Channels []string `json:"channels"` // email, slack, discord
+	Enabled  *bool    `json:"enabled"`
+}
+
+func findScheduledReport(w http.ResponseWriter, r *http.Request, organizationID uint) (models.ScheduledReport, bool) {
+	var report models.ScheduledReport
+	result := database.DB.Where("id = ? AND organization_id = ?", mux.Vars(r)["id"], organizationID).First(&report)
+	if result.Error != nil {
+		http.Error(w, "Scheduled report not found or unauthorized", http.StatusNotFound)
+		return report, false
+	}
+	return report, true
+}
+
+// applyScheduledReportRequest validates req and copies it onto report,
+// recomputing the next run from now.
+func applyScheduledReportRequest(w http.ResponseWriter, r *http.Request, report *models.ScheduledReport) bool {
+	var req ScheduledReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return false
+	}
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.Schedule) == "" {
+		http.Error(w, "Name and schedule are required", http.StatusBadRequest)
+		return false
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	next, err := services.NextReportRun(req.Schedule, req.Timezone, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	channels := make([]string, 0, len(req.Channels))
+	for _, channel := range req.Channels {
+		channel = strings.ToLower(channel)
+		if channel != "email" && channel != "slack" && channel != "discord" {
+			http.Error(w, "Channels must be email, slack or discord", http.StatusBadRequest)
+			return false
+		}
+		channels = append(channels, channel)
+	}
+	channelsJSON, _ := json.Marshal(channels)
+
+	report.Name = strings.TrimSpace(req.Name)
+	report.Schedule = strings.TrimSpace(req.Schedule)
+	report.Timezone = req.Timezone
+	report.Channels = string(channelsJSON)
+	report.NextRunAt = next
+	if req.Enabled != nil {
+		report.Enabled = *req.Enabled
+	}
+	return true
+}
+
+func GetScheduledReports(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	var reports []models.ScheduledReport
+	if err := database.DB.Where("organization_id = ?", scope.OrganizationID).Order("next_run_at").Find(&reports).Error; err != nil {
+		http.Error(w, "Error fetching scheduled reports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+func CreateScheduledReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	report := models.ScheduledReport{UserID: scope.UserID, OrganizationID: scope.OrganizationID, Enabled: true}
+	if !applyScheduledReportRequest(w, r, &report) {
+		return
+	}
+	if err := database.DB.Create(&report).Error; err != nil {
+		http.Error(w, "Error saving scheduled report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
+
+func UpdateScheduledReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	report, found := findScheduledReport(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+	if !applyScheduledReportRequest(w, r, &report) {
+		return
+	}
+	// Only the request's fields: the worker may be recording a run at the same
+	// time, and Save would overwrite its last_run_at and last_error.
+	err := database.DB.Model(&report).
+		Select("name", "schedule", "timezone", "channels", "next_run_at", "enabled").
+		Updates(&report).Error
+	if err != nil {
+		http.Error(w, "Error saving scheduled report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func DeleteScheduledReport(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	report, found := findScheduledReport(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+	database.DB.Delete(&report)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Scheduled report deleted successfully"})
+}
+
+// SendScheduledReportNow sends the report immediately without moving its
+// schedule, so users can check what it looks like in their channels.
+func SendScheduledReportNow(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ManageNotifications)
+	if !ok {
+		return
+	}
+
+	report, found := findScheduledReport(w, r, scope.OrganizationID)
+	if !found {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := services.SendScheduledReport(report, time.Now().UTC()); err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(NotificationTestResponse{Success: false, Message: "Report could not be sent", Error: err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(NotificationTestResponse{Success: true, Message: "Report sent"})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/risk"
+)
+
+type ScreenRequest struct {
+	EnvelopeXDR string `json:"envelope_xdr"`
+	Network     string `json:"network"` // testnet (default), public
+}
+
+// ScreenTransaction checks an unsigned or signed transaction envelope before it
+// is submitted and answers allow, warn or block. It never submits anything.
+func ScreenTransaction(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ScreenTransactions)
+	if !ok {
+		return
+	}
+
+	var req ScreenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.EnvelopeXDR == "" {
+		http.Error(w, "envelope_xdr is required", http.StatusBadRequest)
+		return
+	}
+	if req.Network == "" {
+		req.Network = "testnet"
+	}
+	if req.Network != "testnet" && req.Network != "public" {
+		http.Error(w, "Network must be testnet or public", http.StatusBadRequest)
+		return
+	}
+
+	result, err := risk.Screen(req.EnvelopeXDR, req.Network, scope.OrganizationID)
+	if err != nil {
+		if _, ok := err.(risk.ErrInvalidEnvelope); ok {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Error screening transaction: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/middleware"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"github.com/gorilla/mux"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// clientIP is the address a request came from, preferring the first
+// X-Forwarded-For entry set by a reverse proxy. It is only shown to users.
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// writeTokens answers with an access token for session and its refresh token.
+func writeTokens(w http.ResponseWriter, session models.Session, refreshToken string, message string) {
+	accessToken, expiresAt, err := middleware.SignAccessToken(session.UserID, session.ID, services.AccessTokenTTL())
+	if err != nil {
+		http.Error(w, "Error generating token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LoginResponse{
+		Message:      message,
+		Token:        accessToken,
+		ExpiresAt:    &expiresAt,
+		RefreshToken: refreshToken,
+	})
+}
+
+// issueSession signs user in on the requesting device.
+func issueSession(w http.ResponseWriter, r *http.Request, user models.User, message string) {
+	session, refreshToken, err := services.CreateSession(int(user.ID), r.UserAgent(), clientIP(r))
+	if err != nil {
+		fmt.Println("❌ Error creating session:", err)
+		http.Error(w, "Error creating session", http.StatusInternalServerError)
+		return
+	}
+	writeTokens(w, session, refreshToken, message)
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new
+// refresh token. Each refresh token works once.
+func RefreshSession(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "A refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	session, refreshToken, err := services.RotateSession(req.RefreshToken)
+	if errors.Is(err, services.ErrRefreshTokenReused) {
+		fmt.Printf("⚠️ Revoked session %d of user %d after refresh token reuse\n", session.ID, session.UserID)
+	}
+	if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error refreshing session", http.StatusInternalServerError)
+		return
+	}
+	writeTokens(w, session, refreshToken, "Session refreshed")
+}
+
+// Logout revokes the session of the calling access token.
+func Logout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := r.Context().Value("session_id").(uint)
+
+	if _, err := services.RevokeSession(userID, sessionID); err != nil {
+		http.Error(w, "Error logging out", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out successfully"})
+}
+
+// LogoutAllSessions revokes every session of the caller, including the
+// current one.
+func LogoutAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	revoked, err := services.RevokeUserSessions(userID)
+	if err != nil {
+		http.Error(w, "Error logging out", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": fmt.Sprintf("Logged out of %d sessions", revoked)})
+}
+
+// GetSessions lists the devices the caller is signed in on.
+func GetSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+	currentID, _ := r.Context().Value("session_id").(uint)
+
+	sessions, err := services.ActiveSessions(userID)
+	if err != nil {
+		http.Error(w, "Error fetching sessions", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeUserSession signs the caller out of one of their devices.
+func RevokeUserSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+	revoked, err := services.RevokeSession(userID, uint(sessionID))
+	if err != nil {
+		http.Error(w, "Error revoking session", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Session not found or already revoked", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Session revoked successfully"})
+}
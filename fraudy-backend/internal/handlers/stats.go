@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"gorm.io/gorm"
+)
+
+const maxTopAccounts = 100
+
+type StatCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+type ActivityStats struct {
+	Total    int64       `json:"total"`
+	ByRule   []StatCount `json:"by_rule"`
+	ByFlag   []StatCount `json:"by_flag"`
+	ByStatus []StatCount `json:"by_status"`
+	ByDay    []StatCount `json:"by_day"` // key is YYYY-MM-DD (UTC)
+}
+
+type RiskyAccount struct {
+	Account        string    `json:"account"`
+	Network        string    `json:"network"`
+	Activities     int64     `json:"activities"`
+	HighFlags      int64     `json:"high_flags"`
+	ConfirmedFraud int64     `json:"confirmed_fraud"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+type ChannelDeliveryStats struct {
+	Channel     string  `json:"channel"`
+	Sent        int64   `json:"sent"`
+	Failed      int64   `json:"failed"`
+	Suppressed  int64   `json:"suppressed"`
+	Throttled   int64   `json:"throttled"`
+	Queued      int64   `json:"queued"`
+	SuccessRate float64 `json:"success_rate"` // sent / (sent + failed); 1 when nothing was attempted
+}
+
+type WalletStats struct {
+	MonitoredWallets int64       `json:"monitored_wallets"`
+	Alerts           int64       `json:"alerts"`
+	ByNetwork        []StatCount `json:"by_network"` // distinct wallets per network
+	ByRule           []StatCount `json:"by_rule"`    // alerts per rule
+}
+
+// organizationActivities is the organization's fraud activities within the
+// from/to range.
+func organizationActivities(r *http.Request, organizationID uint) (*gorm.DB, error) {
+	return applyDateRange(database.DB.Model(&models.FraudActivity{}).Where("organization_id = ?", organizationID), r, "created_at")
+}
+
+func countBy(query *gorm.DB, expression string) ([]StatCount, error) {
+	counts := []StatCount{}
+	err := query.Session(&gorm.Session{}).
+		Select(expression + " AS key, COUNT(*) AS count").
+		Group("key").Order("count DESC").Scan(&counts).Error
+	return counts, err
+}
+
+// GetActivityStats counts the organization's fraud activities per rule, flag,
+// status and day. It accepts the from and to filters.
+func GetActivityStats(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	query, err := organizationActivities(r, scope.OrganizationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var stats ActivityStats
+	if err := query.Session(&gorm.Session{}).Count(&stats.Total).Error; err != nil {
+		writeListError(w, err, "Error computing activity stats")
+		return
+	}
+	for _, group := range []struct {
+		expression string
+		target     *[]StatCount
+	}{
+		{"type", &stats.ByRule},
+		{"flag", &stats.ByFlag},
+		{"status", &stats.ByStatus},
+	} {
+		if *group.target, err = countBy(query, group.expression); err != nil {
+			writeListError(w, err, "Error computing activity stats")
+			return
+		}
+	}
+
+	stats.ByDay = []StatCount{}
+	err = query.Session(&gorm.Session{}).
+		Select("to_char(date_trunc('day', created_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD') AS key, COUNT(*) AS count").
+		Group("key").Order("key").Scan(&stats.ByDay).Error
+	if err != nil {
+		writeListError(w, err, "Error computing activity stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetTopRiskyAccounts ranks the accounts in the organization's fraud activities by
+// confirmed fraud, then high flags, then number of activities. Each network
+// is ranked separately and activities are counted once per transaction, however
+// many alerts recorded them. Cases judged false positives are left out, and confirmed
+// fraud still counts once the case is resolved. Accepts from, to
+// and limit (default 10).
+func GetTopRiskyAccounts(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxTopAccounts)
+	}
+	query, err := organizationActivities(r, scope.OrganizationID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	accounts := []RiskyAccount{}
+	err = query.
+		Select("account, network, COUNT(DISTINCT transaction_hash) AS activities, "+
+			"COUNT(DISTINCT transaction_hash) FILTER (WHERE LOWER(flag) = 'high') AS high_flags, "+
+			"COUNT(DISTINCT transaction_hash) FILTER (WHERE verdict = ?) AS confirmed_fraud, "+
+			"MAX(created_at) AS last_activity_at", models.CaseStatusConfirmedFraud).
+		Where("verdict <> ?", models.CaseStatusFalsePositive).
+		Group("account, network").
+		Order("confirmed_fraud DESC, high_flags DESC, activities DESC").
+		Limit(limit).Scan(&accounts).Error
+	if err != nil {
+		writeListError(w, err, "Error computing top risky accounts")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// GetNotificationStats reports delivery outcomes and success rate per channel
+// for the organization's alerts. Accepts from and to.
+func GetNotificationStats(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	query, err := applyDateRange(database.DB.Model(&models.NotificationDelivery{}).Where("organization_id = ?", scope.OrganizationID), r, "created_at")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rows []struct {
+		Channel string
+		Status  string
+		Count   int64
+	}
+	if err := query.Select("channel, status, COUNT(*) AS count").Group("channel, status").Order("channel").Scan(&rows).Error; err != nil {
+		writeListError(w, err, "Error computing notification stats")
+		return
+	}
+
+	stats := []ChannelDeliveryStats{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, seen := index[row.Channel]
+		if !seen {
+			i = len(stats)
+			index[row.Channel] = i
+			stats = append(stats, ChannelDeliveryStats{Channel: row.Channel})
+		}
+		switch row.Status {
+		case services.DeliverySent:
+			stats[i].Sent = row.Count
+		case services.DeliveryFailed:
+			stats[i].Failed = row.Count
+		case services.DeliverySuppressed:
+			stats[i].Suppressed = row.Count
+		case services.DeliveryThrottled:
+			stats[i].Throttled = row.Count
+		case services.DeliveryQueued:
+			stats[i].Queued = row.Count
+		}
+	}
+	for i := range stats {
+		stats[i].SuccessRate = 1
+		if attempted := stats[i].Sent + stats[i].Failed; attempted > 0 {
+			stats[i].SuccessRate = float64(stats[i].Sent) / float64(attempted)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetWalletStats counts the wallets the organization monitors and the alerts on them.
+func GetWalletStats(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	alerts := database.DB.Model(&models.Alert{}).Where("organization_id = ?", scope.OrganizationID)
+	stats := WalletStats{ByNetwork: []StatCount{}}
+	err := alerts.Session(&gorm.Session{}).Count(&stats.Alerts).Error
+	if err == nil {
+		err = alerts.Session(&gorm.Session{}).Distinct("wallet_id").Count(&stats.MonitoredWallets).Error
+	}
+	if err == nil {
+		err = alerts.Session(&gorm.Session{}).
+			Select("network AS key, COUNT(DISTINCT wallet_id) AS count").
+			Group("network").Order("count DESC").Scan(&stats.ByNetwork).Error
+	}
+	if err == nil {
+		stats.ByRule, err = countBy(alerts, "rule_type")
+	}
+	if err != nil {
+		writeListError(w, err, "Error computing wallet stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
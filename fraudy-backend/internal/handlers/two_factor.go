@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// currentUser loads the calling user.
+func currentUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	var user models.User
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return user, false
+	}
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return user, false
+	}
+	return user, true
+}
+
+// writeTwoFactorError answers with the status that fits a two-factor error.
+func writeTwoFactorError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTOTPCode):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTOTPLocked):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, services.ErrTOTPAlreadyEnabled), errors.Is(err, services.ErrTOTPNotEnabled),
+		errors.Is(err, services.ErrTOTPNotEnrolled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		fmt.Printf("❌ Error %s: %v\n", action, err)
+		http.Error(w, "Error "+action, http.StatusInternalServerError)
+	}
+}
+
+// EnrollTwoFactor starts TOTP enrollment and returns the secret with its
+// otpauth:// URI for the frontend to show as a QR code.
+func EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := services.BeginTOTPEnrollment(user)
+	if err != nil {
+		writeTwoFactorError(w, err, "starting two-factor enrollment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmTwoFactor turns two-factor authentication on with a code from the
+// enrolled authenticator and returns the recovery codes, which are only
+// shown this once.
+func ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "A code is required", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := services.ConfirmTOTPEnrollment(user, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err, "enabling two-factor authentication")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{
+		Message:       "Two-factor authentication enabled",
+		RecoveryCodes: codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes.
+func RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "A code is required", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(user, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err, "generating recovery codes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{
+		Message:       "Recovery codes regenerated",
+		RecoveryCodes: codes,
+	})
+}
+
+// DisableTwoFactor turns two-factor authentication off with a TOTP or
+// recovery code.
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "A code is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := services.DisableTOTP(user, req.Code); err != nil {
+		writeTwoFactorError(w, err, "disabling two-factor authentication")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor authentication disabled"})
+}
+
+// LoginTwoFactor finishes a login with the challenge token LoginUser
+// returned and a TOTP or recovery code.
+func LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		http.Error(w, "A challenge token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := services.CompleteLoginChallenge(req.ChallengeToken, req.Code)
+	if errors.Is(err, services.ErrInvalidAccountToken) {
+		http.Error(w, "Invalid or expired login challenge", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		writeTwoFactorError(w, err, "verifying two-factor code")
+		return
+	}
+
+	issueSession(w, r, user, "Login successful")
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"fraudy-backend/internal/access"
+	"fraudy-backend/internal/wallets"
+	"github.com/gorilla/mux"
+	"github.com/stellar/go/strkey"
+)
+
+const maxWalletHistory = 200
+
+// GetWalletDetails backs the wallet page: on-chain state and recent history
+// from Horizon plus the organization's alerts and fraud activities on the account.
+// Query parameters: network (testnet or public) and limit (recent records, default 20).
+func GetWalletDetails(w http.ResponseWriter, r *http.Request) {
+	scope, ok := authorize(w, r, access.ViewData)
+	if !ok {
+		return
+	}
+
+	account := mux.Vars(r)["account"]
+	if !strkey.IsValidEd25519PublicKey(account) {
+		http.Error(w, "Account must be a valid Stellar account ID", http.StatusBadRequest)
+		return
+	}
+	network := r.URL.Query().Get("network")
+	if network == "" {
+		network = "testnet"
+	}
+	if network != "testnet" && network != "public" {
+		http.Error(w, "Network must be testnet or public", http.StatusBadRequest)
+		return
+	}
+	limit := 20
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxWalletHistory)
+	}
+
+	details, err := wallets.GetDetails(account, network, scope.OrganizationID, uint(limit))
+	if err != nil {
+		http.Error(w, "Error fetching wallet details: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
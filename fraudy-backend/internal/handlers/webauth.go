@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"fraudy-backend/internal/services"
+	"fraudy-backend/internal/webauth"
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+type StellarChallengeResponse struct {
+	Transaction string `json:"transaction"`
+}
+
+type StellarAccountResponse struct {
+	Account  string    `json:"account"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// verifyStellarChallenge reads a signed SEP-10 challenge from the body and
+// returns the account it proves control of.
+func verifyStellarChallenge(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req StellarChallengeResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Transaction == "" {
+		http.Error(w, "A signed challenge transaction is required", http.StatusBadRequest)
+		return "", false
+	}
+
+	account, err := webauth.Verify(req.Transaction)
+	switch {
+	case errors.Is(err, webauth.ErrNotConfigured):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return "", false
+	case errors.Is(err, webauth.ErrInvalidChallenge), errors.Is(err, webauth.ErrChallengeReused):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", false
+	case err != nil:
+		fmt.Println("❌ Error verifying challenge transaction:", err)
+		http.Error(w, "Error verifying challenge transaction", http.StatusInternalServerError)
+		return "", false
+	}
+	return account, true
+}
+
+// GetStellarChallenge returns a SEP-10 challenge transaction for the account
+// query parameter, to be signed by the wallet and posted back.
+func GetStellarChallenge(w http.ResponseWriter, r *http.Request) {
+	account := r.URL.Query().Get("account")
+	if account == "" {
+		http.Error(w, "account is required", http.StatusBadRequest)
+		return
+	}
+
+	challenge, err := webauth.NewChallenge(account)
+	switch {
+	case errors.Is(err, webauth.ErrNotConfigured):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case errors.Is(err, webauth.ErrInvalidChallenge):
+		http.Error(w, "Invalid Stellar account", http.StatusBadRequest)
+		return
+	case err != nil:
+		fmt.Println("❌ Error building challenge transaction:", err)
+		http.Error(w, "Error building challenge transaction", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(challenge)
+}
+
+// StellarLogin signs in the user linked to the account that signed the
+// challenge. It answers like LoginUser, including two-factor challenges.
+func StellarLogin(w http.ResponseWriter, r *http.Request) {
+	account, ok := verifyStellarChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := services.FindUserByStellarAccount(account)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, "No user is linked to this Stellar account", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error finding user", http.StatusInternalServerError)
+		return
+	}
+
+	completeLogin(w, r, user)
+}
+
+// GetStellarAccounts lists the Stellar accounts the caller can sign in with.
+func GetStellarAccounts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	accounts, err := services.UserStellarAccounts(userID)
+	if err != nil {
+		http.Error(w, "Error fetching Stellar accounts", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]StellarAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		response = append(response, StellarAccountResponse{Account: account.Account, LinkedAt: account.CreatedAt})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// LinkStellarAccount lets the caller sign in with the account that signed
+// the challenge.
+func LinkStellarAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+	account, ok := verifyStellarChallenge(w, r)
+	if !ok {
+		return
+	}
+
+	linked, err := services.LinkStellarAccount(userID, account)
+	if errors.Is(err, services.ErrStellarAccountLinked) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error linking Stellar account", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(StellarAccountResponse{Account: linked.Account, LinkedAt: linked.CreatedAt})
+}
+
+// UnlinkStellarAccount stops the caller signing in with a Stellar account.
+func UnlinkStellarAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value("user_id").(int)
+	if !ok {
+		http.Error(w, "Unauthorized: Unable to extract user ID", http.StatusUnauthorized)
+		return
+	}
+
+	unlinked, err := services.UnlinkStellarAccount(userID, mux.Vars(r)["account"])
+	if err != nil {
+		http.Error(w, "Error unlinking Stellar account", http.StatusInternalServerError)
+		return
+	}
+	if !unlinked {
+		http.Error(w, "Stellar account not linked", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Stellar account unlinked successfully"})
+}
@@ -1,68 +1,161 @@
-package middleware
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"net/http"
-	"os"
-	"strings"
-
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/joho/godotenv"
-)
-
-type Claims struct {
-	UserID int `json:"user_id"`
-	jwt.RegisteredClaims
-}
-
-var jwtKey []byte
-
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️ Warning: No .env file found")
-	}
-
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		log.Fatal("❌ Error: JWT_SECRET is not set in .env")
-	}
-
-	fmt.Println("🔑 Middleware Loaded JWT Secret:", secret)
-	jwtKey = []byte(secret)
-}
-
-func JWTAuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			fmt.Println("❌ Missing Authorization header")
-			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			fmt.Println("❌ Invalid token format")
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-			return
-		}
-
-		fmt.Println("🔑 Incoming JWT Token:", tokenString)
-
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			fmt.Println("❌ Invalid or expired token:", err)
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
-			return
-		}
-
-		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
-}
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	jwtkeys "fraudy-backend/pkg/jwt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type Claims struct {
+	UserID    int  `json:"user_id"`
+	SessionID uint `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+var signingKeys *jwtkeys.Keyring
+
+// sessionActive checks that the session of a request is still signed in.
+var sessionActive = services.SessionActive
+
+// InitSigningKeys loads the keys access tokens are signed with. JWT_KEYS is a
+// comma separated list of "id:alg:value" entries (see jwtkeys.NewKeyring) and
+// JWT_ACTIVE_KEY_ID selects the key for new tokens. Without JWT_KEYS the
+// single JWT_SECRET is used as an HS256 key.
+func InitSigningKeys() error {
+	var err error
+	if spec := os.Getenv("JWT_KEYS"); spec != "" {
+		signingKeys, err = jwtkeys.NewKeyring(spec, os.Getenv("JWT_ACTIVE_KEY_ID"))
+	} else {
+		signingKeys, err = jwtkeys.NewHMACKeyring(os.Getenv("JWT_SECRET"))
+	}
+	if err != nil {
+		return fmt.Errorf("❌ Error loading JWT signing keys: %v", err)
+	}
+	fmt.Printf("🔑 Signing access tokens with key %q\n", signingKeys.ActiveKeyID())
+	return nil
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+func JWKS() jwtkeys.JWKSet {
+	return signingKeys.JWKS()
+}
+
+// SignAccessToken issues a short-lived access token for a session of userID.
+func SignAccessToken(userID int, sessionID uint, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := signingKeys.Sign(claims)
+	return token, expiresAt, err
+}
+
+func JWTAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		// EventSource cannot set headers, so event streams pass a stream ticket
+		// in the query. Access tokens and API keys are never read from URLs.
+		if authHeader == "" && r.URL.Query().Get("ticket") != "" {
+			if !isEventStreamRequest(r) {
+				fmt.Printf("❌ Stream ticket used for %s %s\n", r.Method, r.URL.Path)
+				http.Error(w, "Stream tickets only open the event stream", http.StatusUnauthorized)
+				return
+			}
+			authenticateStreamTicket(w, r, next, r.URL.Query().Get("ticket"))
+			return
+		}
+		if authHeader == "" {
+			fmt.Println("❌ Missing Authorization header")
+			http.Error(w, "Authorization header missing", http.StatusUnauthorized)
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == authHeader {
+			fmt.Println("❌ Invalid token format")
+			http.Error(w, "Invalid token format", http.StatusUnauthorized)
+			return
+		}
+
+		if strings.HasPrefix(tokenString, services.APIKeyPrefix) {
+			authenticateAPIKey(w, r, next, tokenString)
+			return
+		}
+
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, signingKeys.Keyfunc)
+
+		if err != nil || !token.Valid {
+			fmt.Println("❌ Invalid or expired token:", err)
+			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+		if !sessionActive(claims.SessionID, claims.UserID) {
+			fmt.Printf("❌ Session %d of user %d is revoked or expired\n", claims.SessionID, claims.UserID)
+			http.Error(w, "Session revoked or expired", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
+		ctx = context.WithValue(ctx, "session_id", claims.SessionID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isEventStreamRequest reports whether r opens the event stream, the only
+// request a stream ticket authenticates.
+func isEventStreamRequest(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.URL.Path == "/api/events" &&
+		r.Header.Get("Accept") == "text/event-stream"
+}
+
+// authenticateStreamTicket serves r as the session a stream ticket was issued
+// to, consuming the ticket.
+func authenticateStreamTicket(w http.ResponseWriter, r *http.Request, next http.Handler, ticket string) {
+	streamTicket, err := services.ConsumeAccountToken(ticket, models.TokenPurposeStreamTicket)
+	if err != nil {
+		fmt.Println("❌ Invalid stream ticket:", err)
+		http.Error(w, "Invalid or expired stream ticket", http.StatusUnauthorized)
+		return
+	}
+	if streamTicket.SessionID == nil || !sessionActive(*streamTicket.SessionID, streamTicket.UserID) {
+		fmt.Printf("❌ Stream ticket of user %d belongs to a revoked or expired session\n", streamTicket.UserID)
+		http.Error(w, "Session revoked or expired", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "user_id", streamTicket.UserID)
+	ctx = context.WithValue(ctx, "session_id", *streamTicket.SessionID)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// authenticateAPIKey serves r as the API key raw. API key requests carry no
+// user_id, so handlers for the signed-in user's own account reject them;
+// authorize checks the key's scopes instead.
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, raw string) {
+	key, err := services.AuthenticateAPIKey(raw)
+	if err != nil {
+		fmt.Println("❌ Invalid API key:", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), "api_key_user_id", key.UserID)
+	ctx = context.WithValue(ctx, "api_key_organization_id", key.OrganizationID)
+	ctx = context.WithValue(ctx, "scopes", services.APIKeyScopes(key))
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/services"
+	jwtkeys "fraudy-backend/pkg/jwt"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamTicketOnlyOpensTheEventStream(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s reached the handler with a stream ticket", r.Method, r.URL.Path)
+	})
+
+	for _, request := range []struct {
+		method string
+		path   string
+		accept string
+	}{
+		{http.MethodPost, "/api/api-keys", "text/event-stream"},
+		{http.MethodPost, "/api/events/ticket", "text/event-stream"},
+		{http.MethodGet, "/api/alerts", "text/event-stream"},
+		{http.MethodPost, "/api/events", "text/event-stream"},
+		{http.MethodGet, "/api/events", "application/json"},
+	} {
+		r := httptest.NewRequest(request.method, request.path+"?ticket=abc", nil)
+		r.Header.Set("Accept", request.accept)
+		w := httptest.NewRecorder()
+
+		JWTAuthMiddleware(next).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", request.method, request.path)
+		assert.Contains(t, w.Body.String(), "Stream tickets only open the event stream")
+	}
+
+	events := httptest.NewRequest(http.MethodGet, "/api/events?ticket=abc", nil)
+	events.Header.Set("Accept", "text/event-stream")
+	assert.True(t, isEventStreamRequest(events))
+}
+
+func TestAccessTokenOfRevokedSessionIsRejected(t *testing.T) {
+	keys, err := jwtkeys.NewHMACKeyring(strings.Repeat("s", 32))
+	require.NoError(t, err)
+	signingKeys = keys
+	t.Cleanup(func() { signingKeys = nil; sessionActive = services.SessionActive })
+
+	token, _, err := SignAccessToken(4, 9, time.Minute)
+	require.NoError(t, err)
+	active := map[uint]bool{9: true}
+	sessionActive = func(sessionID uint, userID int) bool {
+		return userID == 4 && active[sessionID]
+	}
+
+	var served []uint
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = append(served, r.Context().Value("session_id").(uint))
+	})
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/alerts", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		JWTAuthMiddleware(next).ServeHTTP(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve().Code)
+	assert.Equal(t, []uint{9}, served)
+
+	active[9] = false
+	w := serve()
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Session revoked or expired")
+	assert.Equal(t, []uint{9}, served, "revoked session reached the handler")
+}
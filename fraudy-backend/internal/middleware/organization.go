@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"fraudy-backend/internal/services"
+)
+
+// OrganizationMiddleware resolves the organization a request acts in from the
+// X-Organization-ID header, defaulting to the caller's oldest membership, and
+// adds its ID and the caller's role to the context. Personal API keys act in
+// the organizations of their user; organization keys only in their own. It
+// must run after JWTAuthMiddleware.
+func OrganizationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value("user_id").(int)
+		if !ok {
+			userID, ok = r.Context().Value("api_key_user_id").(int)
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if keyOrganizationID, _ := r.Context().Value("api_key_organization_id").(uint); keyOrganizationID != 0 {
+			header := r.Header.Get("X-Organization-ID")
+			if header != "" && header != strconv.FormatUint(uint64(keyOrganizationID), 10) {
+				http.Error(w, "Forbidden: This API key belongs to another organization", http.StatusForbidden)
+				return
+			}
+			ctx := context.WithValue(r.Context(), "organization_id", keyOrganizationID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		header := r.Header.Get("X-Organization-ID")
+		// EventSource cannot set headers, so event streams may pass it in the query.
+		if header == "" && r.Header.Get("Accept") == "text/event-stream" {
+			header = r.URL.Query().Get("organization_id")
+		}
+		var organizationID uint64
+		if header != "" {
+			parsed, err := strconv.ParseUint(header, 10, 64)
+			if err != nil || parsed == 0 {
+				http.Error(w, "Invalid X-Organization-ID header", http.StatusBadRequest)
+				return
+			}
+			organizationID = parsed
+		}
+
+		membership, err := services.FindMembership(userID, uint(organizationID))
+		if err != nil {
+			if header != "" {
+				fmt.Printf("❌ User %d is not a member of organization %d\n", userID, organizationID)
+				http.Error(w, "Forbidden: You are not a member of this organization", http.StatusForbidden)
+				return
+			}
+			// Users without an organization may still list and create them.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "organization_id", membership.OrganizationID)
+		ctx = context.WithValue(ctx, "role", membership.Role)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Purposes of an AccountToken.
+const (
+	TokenPurposeVerifyEmail    = "verify_email"
+	TokenPurposePasswordReset  = "password_reset"
+	TokenPurposeLoginChallenge = "login_challenge"
+	TokenPurposeStreamTicket   = "stream_ticket"
+)
+
+// AccountToken is a single-use, expiring token emailed to a user to verify
+// their address or reset their password, handed out after a correct password
+// to finish a two-factor login, or exchanged for an event stream. Only its
+// hash is stored.
+type AccountToken struct {
+	gorm.Model
+	UserID    int       `gorm:"not null;index"`
+	Purpose   string    `gorm:"size:30;not null"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	Attempts  int `gorm:"not null;default:0"` // failed second factor attempts on a login challenge
+	SessionID *uint // session a stream ticket was issued to
+}
@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	ListKindBlocklist = "blocklist"
+	ListKindAllowlist = "allowlist"
+)
+
+// AddressList is a named set of accounts or domains an organization maintains,
+// such as known scam accounts, exchange hot wallets or its own treasury.
+// Allowlisted counterparties never raise alerts for the organization.
+type AddressList struct {
+	gorm.Model
+	UserID         int    `gorm:"not null;index"` // creator
+	OrganizationID uint   `gorm:"not null;default:0;index"`
+	Name           string `gorm:"size:255;not null"`
+	Kind           string `gorm:"size:20;not null;default:'blocklist'"` // blocklist, allowlist
+	Description    string `gorm:"type:text"`
+}
+
+type AddressListEntry struct {
+	ID      uint   `gorm:"primarykey"`
+	ListID  uint   `gorm:"not null;uniqueIndex:idx_list_entry_address"`
+	Address string `gorm:"size:255;not null;uniqueIndex:idx_list_entry_address;index"` // account ID or lowercased domain
+	Label   string `gorm:"size:255"`
+}
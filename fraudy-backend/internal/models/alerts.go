@@ -1,19 +1,22 @@
-package models
-
-import (
-	"gorm.io/gorm"
-)
-
-type Alert struct {
-	gorm.Model
-	UserID                 int           `gorm:"not null"`
-	AlertName              string         `gorm:"size:255;not null"`
-	RuleType               string         `gorm:"size:50;not null"` 
-	NotificationPreferences string         `gorm:"type:jsonb"` 
-	WalletID           string  `gorm:"size:255;not null"`
-	Flag                   string `gorm:"size:50;not null"`
-	TransactionThreshold float64 `gorm:"not null"`
-	TimeFrame          int     `gorm:"not null"` 
-	TransactionStatus  bool    `gorm:"not null"`
-}
-
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+type Alert struct {
+	gorm.Model
+	UserID                 int           `gorm:"not null"`
+	OrganizationID         uint          `gorm:"not null;default:0;index"`
+	AlertName              string         `gorm:"size:255;not null"`
+	RuleType               string         `gorm:"size:50;not null"` 
+	NotificationPreferences string         `gorm:"type:jsonb"` 
+	WalletID           string  `gorm:"size:255;not null"`
+	Flag                   string `gorm:"size:50;not null"`
+	TransactionThreshold float64 `gorm:"not null"`
+	TimeFrame          int     `gorm:"not null"` 
+	TransactionStatus  bool    `gorm:"not null"`
+	Network            string  `gorm:"size:20;not null;default:'testnet'"` // testnet, public
+	ListID             *uint   `gorm:"index"` // address list checked by the listPayment rule
+}
+
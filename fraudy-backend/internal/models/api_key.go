@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey lets integrations call the API without signing in. A personal key
+// acts as its user, limited by its scopes; an organization key is bound to
+// one organization and limited only by its scopes. Only its hash is stored.
+type APIKey struct {
+	gorm.Model
+	UserID         int    `gorm:"not null;index"`           // who created the key
+	OrganizationID uint   `gorm:"not null;default:0;index"` // set for organization keys
+	Name           string `gorm:"size:100;not null"`
+	Prefix         string `gorm:"size:20;not null"` // start of the key, to tell keys apart
+	KeyHash        string `gorm:"size:64;not null;uniqueIndex"`
+	Scopes         string `gorm:"size:255;not null"` // comma separated access.Scope values
+	LastUsedAt     *time.Time
+	ExpiresAt      *time.Time
+	RevokedAt      *time.Time
+}
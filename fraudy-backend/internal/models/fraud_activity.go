@@ -1,13 +1,33 @@
-package models
-
-import "gorm.io/gorm"
-
-type FraudActivity struct {
-	gorm.Model
-	Account        string `gorm:"size:100;not null"`
-	Type          string `gorm:"size:50;not null"`  
-	TransactionHash string `gorm:"size:100;not null"` 
-	Sequence       string `gorm:"size:50;not null"`  
-	FailureCount   int    
-	Flag            string `gorm:"size:20;not null"`
-}
+package models
+
+import "gorm.io/gorm"
+
+// Case statuses a FraudActivity moves through during an investigation.
+const (
+	CaseStatusNew            = "new"
+	CaseStatusAcknowledged   = "acknowledged"
+	CaseStatusInvestigating  = "investigating"
+	CaseStatusConfirmedFraud = "confirmed_fraud"
+	CaseStatusFalsePositive  = "false_positive"
+	CaseStatusResolved       = "resolved"
+)
+
+type FraudActivity struct {
+	gorm.Model
+	AlertID         uint   `gorm:"index"`
+	UserID          int    `gorm:"index"`
+	OrganizationID  uint   `gorm:"not null;default:0;index"`
+	Account        string `gorm:"size:100;not null"`
+	Type          string `gorm:"size:50;not null"`  
+	TransactionHash string `gorm:"size:100;not null"` 
+	TransactionHashes string `gorm:"type:jsonb;default:'[]'"`
+	Sequence       string `gorm:"size:50;not null"`  
+	FailureCount   int    
+	Counterparty    string `gorm:"size:255;index"` // account on the other side, when the rule involves one
+	Flag            string `gorm:"size:20;not null"`
+	Network         string `gorm:"size:20;not null;default:'testnet';index"`
+	Status          string `gorm:"size:30;not null;default:'new';index"`
+	AssigneeID      *uint
+	ResolutionNotes string `gorm:"type:text"`
+	Verdict         string `gorm:"size:30;not null;default:'';index"` // confirmed_fraud or false_positive once decided, kept when the case is resolved
+}
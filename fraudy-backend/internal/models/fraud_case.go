@@ -0,0 +1,20 @@
+package models
+
+import "gorm.io/gorm"
+
+type FraudActivityComment struct {
+	gorm.Model
+	FraudActivityID uint   `gorm:"not null;index"`
+	UserID          int    `gorm:"not null"`
+	Body            string `gorm:"type:text;not null"`
+}
+
+// FraudActivityStatusChange is one entry in a case's status history.
+type FraudActivityStatusChange struct {
+	gorm.Model
+	FraudActivityID uint   `gorm:"not null;index"`
+	UserID          int    `gorm:"not null"`
+	FromStatus      string `gorm:"size:30;not null"`
+	ToStatus        string `gorm:"size:30;not null"`
+	Note            string `gorm:"type:text"`
+}
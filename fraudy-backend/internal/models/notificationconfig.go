@@ -1,18 +1,24 @@
-package models
-
-import "gorm.io/gorm"
-
-type NotificationConfig struct {
-	gorm.Model
-	UserID          int    `gorm:"not null"`
-	ConfigName      string `gorm:"size:255;not null"`
-	NotificationType string `gorm:"size:50;not null"` // slack, email, telegram, discord
-	SlackWebhook    string `gorm:"size:255"`
-	EmailSender     string `gorm:"size:255"`
-	EmailPassword   string `gorm:"size:255"`
-	SMTPServer      string `gorm:"size:255"`
-	SMTPPort        string `gorm:"size:10"`
-	RecipientEmails string `gorm:"type:jsonb"`
-	TelegramChatID  string `gorm:"size:255"`
-	DiscordChannel  string `gorm:"size:255"`
-}
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationConfig struct {
+	gorm.Model
+	UserID          int    `gorm:"not null"`
+	OrganizationID  uint   `gorm:"not null;default:0;index"`
+	ConfigName      string `gorm:"size:255;not null"`
+	NotificationType string `gorm:"size:50;not null"` // slack, email, telegram, discord
+	SlackWebhook    string `gorm:"type:text"` // encrypted at rest
+	EmailSender     string `gorm:"size:255"`
+	EmailPassword   string `gorm:"type:text"` // encrypted at rest
+	SMTPServer      string `gorm:"size:255"`
+	SMTPPort        string `gorm:"size:10"`
+	RecipientEmails string `gorm:"type:jsonb"`
+	TelegramChatID  string `gorm:"size:255"`
+	DiscordChannel  string `gorm:"type:text"` // webhook URL, encrypted at rest
+	LastVerifiedAt  *time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// NotificationDelivery records every notification attempt for an alert,
+// including the ones that were suppressed, throttled or queued for a digest.
+type NotificationDelivery struct {
+	gorm.Model
+	AlertID         uint   `gorm:"not null;index"`
+	UserID          int    `gorm:"not null;index"`
+	OrganizationID  uint   `gorm:"not null;default:0;index"`
+	Channel         string `gorm:"size:50;not null"`
+	FraudActivityID uint   `gorm:"index"`
+	Status          string `gorm:"size:20;not null;index"` // sent, failed, suppressed, throttled, queued, sending
+	Error           string `gorm:"type:text"`
+}
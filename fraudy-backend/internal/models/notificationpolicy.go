@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// NotificationPolicy controls how often an alert may notify through a channel.
+// A zero value for any limit disables that limit.
+type NotificationPolicy struct {
+	gorm.Model
+	AlertID           uint   `gorm:"not null;uniqueIndex:idx_policy_alert_channel"`
+	Channel           string `gorm:"size:50;not null;uniqueIndex:idx_policy_alert_channel"` // email, slack, telegram, discord
+	SuppressionWindow int    // minutes during which repeated triggers are suppressed
+	MaxPerHour        int
+	DigestMode        bool
+	DigestInterval    int // minutes between digest messages
+}
@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// NotificationTemplate overrides the built-in template for a channel and
+// event type. Source must define a "subject" and a "body" template.
+type NotificationTemplate struct {
+	gorm.Model
+	UserID         int    `gorm:"not null"`
+	OrganizationID uint   `gorm:"not null;default:0;uniqueIndex:idx_template_org_channel_event"`
+	Channel        string `gorm:"size:50;not null;uniqueIndex:idx_template_org_channel_event"` // email, slack, discord
+	EventType      string `gorm:"size:50;not null;uniqueIndex:idx_template_org_channel_event"` // alert, digest, report
+	Source         string `gorm:"type:text;not null"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// Roles a member can hold in an organization, from most to least privileged.
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleAnalyst = "analyst"
+	RoleViewer  = "viewer"
+)
+
+// Organization owns the alerts, notification configs, address lists and
+// cases its members share. Every user gets a personal organization when they
+// register.
+type Organization struct {
+	gorm.Model
+	Name string `gorm:"size:255;not null"`
+}
+
+type Membership struct {
+	gorm.Model
+	OrganizationID uint   `gorm:"not null;uniqueIndex:idx_membership_org_user"`
+	UserID         int    `gorm:"not null;uniqueIndex:idx_membership_org_user;index"`
+	Role           string `gorm:"size:20;not null;default:'viewer'"` // owner, admin, analyst, viewer
+}
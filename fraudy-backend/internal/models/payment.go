@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Payment is a value transfer collected from Horizon for the transaction
+// graph. Amounts are in units of Asset ("XLM" or "CODE:ISSUER").
+type Payment struct {
+	ID              uint      `gorm:"primarykey"`
+	Network         string    `gorm:"size:20;not null;uniqueIndex:idx_payment_operation"`
+	OperationID     string    `gorm:"size:40;not null;uniqueIndex:idx_payment_operation"`
+	TransactionHash string    `gorm:"size:100;not null"`
+	Type            string    `gorm:"size:50;not null"`
+	FromAccount     string    `gorm:"size:100;not null;index"`
+	ToAccount       string    `gorm:"size:100;not null;index"`
+	Asset           string    `gorm:"size:100;not null"`
+	Amount          float64   `gorm:"type:numeric(30,7);not null"`
+	LedgerCloseTime time.Time `gorm:"not null;index"`
+	CreatedAt       time.Time
+}
+
+// PaymentSync remembers how far the payments of an account have been
+// collected, so the graph only asks Horizon for what is new.
+type PaymentSync struct {
+	ID       uint      `gorm:"primarykey"`
+	Network  string    `gorm:"size:20;not null;uniqueIndex:idx_payment_sync_account"`
+	Account  string    `gorm:"size:100;not null;uniqueIndex:idx_payment_sync_account"`
+	Cursor   string    `gorm:"size:40"`
+	SyncedAt time.Time `gorm:"not null"`
+}
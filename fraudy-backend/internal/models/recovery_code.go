@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the
+// user has lost their authenticator. Only its hash is stored.
+type RecoveryCode struct {
+	gorm.Model
+	UserID   int    `gorm:"not null;index"`
+	CodeHash string `gorm:"size:64;not null;index"`
+	UsedAt   *time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	ReportTypeWallet   = "wallet"
+	ReportTypePhishing = "phishing"
+
+	ReportStatusPending   = "pending"
+	ReportStatusConfirmed = "confirmed"
+	ReportStatusRejected  = "rejected"
+)
+
+// ReportedAddress is a community report of a Stellar account or phishing domain.
+type ReportedAddress struct {
+	gorm.Model
+	Address        string `gorm:"size:255;not null;index"` // account ID or lowercased domain
+	Type           string `gorm:"size:20;not null"`        // wallet, phishing
+	Reason         string `gorm:"type:text;not null"`
+	Evidence       string `gorm:"type:text"`
+	ReportedBy     int    `gorm:"not null;index"`
+	Status         string `gorm:"size:20;not null;default:'pending';index"`
+	ModeratedBy    *int
+	ModerationNote string `gorm:"type:text"`
+	Upvotes        int    `gorm:"not null;default:0"`
+	Downvotes      int    `gorm:"not null;default:0"`
+}
+
+// ReportVote is one user's agreement (+1) or disagreement (-1) with a report.
+type ReportVote struct {
+	gorm.Model
+	ReportID uint `gorm:"not null;uniqueIndex:idx_vote_report_user"`
+	UserID   int  `gorm:"not null;uniqueIndex:idx_vote_report_user"`
+	Value    int  `gorm:"not null"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ScheduledReport sends a summary of an organization's fraud activity through
+// its notification channels on a cron schedule. UserID is the member who set
+// it up.
+type ScheduledReport struct {
+	gorm.Model
+	UserID         int       `gorm:"not null;index"`
+	OrganizationID uint      `gorm:"not null;default:0;index"`
+	Name           string    `gorm:"size:255;not null"`
+	Schedule       string    `gorm:"size:100;not null"`              // standard 5-field cron expression or @daily/@weekly
+	Timezone       string    `gorm:"size:64;not null;default:'UTC'"` // IANA zone the schedule is evaluated in
+	Channels       string    `gorm:"type:jsonb;default:'[]'"`        // e.g. ["email","slack"]
+	Enabled        bool      `gorm:"not null;default:true"`
+	NextRunAt      time.Time `gorm:"not null;index"`
+	LastRunAt      *time.Time
+	LastError      string `gorm:"type:text"`
+}
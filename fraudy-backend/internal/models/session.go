@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Session is one signed-in device. Access tokens name their session, so
+// revoking it logs the device out before the access token expires. Only
+// hashes of refresh tokens are stored.
+type Session struct {
+	gorm.Model
+	UserID            int    `gorm:"not null;index"`
+	RefreshTokenHash  string `gorm:"size:64;not null;uniqueIndex"`
+	PreviousTokenHash string `gorm:"size:64;index"` // the hash rotated out last, to detect reuse
+	UserAgent         string `gorm:"size:512"`
+	IPAddress         string `gorm:"size:64"`
+	LastUsedAt        time.Time
+	ExpiresAt         time.Time `gorm:"not null"`
+	RevokedAt         *time.Time
+}
@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// StellarAccount is a Stellar account a user proved control of with SEP-10
+// and may sign in with. An account belongs to at most one user.
+type StellarAccount struct {
+	gorm.Model
+	UserID  int    `gorm:"not null;index"`
+	Account string `gorm:"size:69;not null;uniqueIndex"` // G... or muxed M... address
+}
@@ -1,10 +1,21 @@
-package models
-
-import "gorm.io/gorm"
-
-type User struct {
-	gorm.Model
-	Username string `gorm:"not null"`
-	Email    string `gorm:"unique;not null"`
-	Password string `gorm:"not null"`
-}
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type User struct {
+	gorm.Model
+	Username string `gorm:"not null"`
+	Email    string `gorm:"unique;not null"`
+	Password string `gorm:"not null"`
+	IsModerator bool `gorm:"not null;default:false"` // may moderate community reports
+	EmailVerifiedAt *time.Time
+	TOTPSecret string `gorm:"column:totp_secret;size:512"` // encrypted with the secrets keyring
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at"` // set once enrollment is confirmed
+	TOTPLastStep int64 `gorm:"column:totp_last_step;not null;default:0"` // last accepted time step, so codes cannot be replayed
+	TOTPFailedAttempts int `gorm:"column:totp_failed_attempts;not null;default:0"` // wrong second-factor codes since the last lockout or success
+	TOTPLockedUntil *time.Time `gorm:"column:totp_locked_until"` // second-factor codes are refused until then
+}
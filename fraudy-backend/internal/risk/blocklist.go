@@ -0,0 +1,46 @@
+package risk
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	operatorBlocklist     map[string]bool
+	operatorBlocklistOnce sync.Once
+)
+
+// loadOperatorBlocklist reads RISK_BLOCKLIST_FILE, one account or domain per
+// line. Blank lines and lines starting with # are ignored.
+func loadOperatorBlocklist() {
+	operatorBlocklist = make(map[string]bool)
+
+	path := os.Getenv("RISK_BLOCKLIST_FILE")
+	if path == "" {
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("⚠️ Could not open risk blocklist %s: %v\n", path, err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		operatorBlocklist[line] = true
+	}
+	fmt.Printf("✅ Loaded %d entries from risk blocklist\n", len(operatorBlocklist))
+}
+
+func isOperatorBlocklisted(address string) bool {
+	operatorBlocklistOnce.Do(loadOperatorBlocklist)
+	return operatorBlocklist[address]
+}
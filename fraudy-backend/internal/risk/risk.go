@@ -0,0 +1,330 @@
+// Package risk scores how safe it is to pay a Stellar account by combining
+// Fraudy's own detections with community reports and on-chain history.
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"fraudy-backend/internal/streaming"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon/operations"
+)
+
+const (
+	LevelLow    = "low"
+	LevelMedium = "medium"
+	LevelHigh   = "high"
+)
+
+// Factor is one signal that contributed to an assessment.
+type Factor struct {
+	Name        string  `json:"name"`
+	Score       float64 `json:"score"`
+	Explanation string  `json:"explanation"`
+}
+
+type Assessment struct {
+	Account   string    `json:"account"`
+	Network   string    `json:"network"`
+	Score     float64   `json:"score"` // 0 (no known risk) to 1
+	Level     string    `json:"level"`
+	Factors   []Factor  `json:"factors"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+func cacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("RISK_CACHE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 10 * time.Minute
+}
+
+func cacheKey(network string, account string) string {
+	return fmt.Sprintf("risk:%s:%s", network, account)
+}
+
+// Assess returns the risk assessment of account, served from Redis when a
+// fresh one was computed within RISK_CACHE_TTL.
+func Assess(account string, network string) (Assessment, error) {
+	ctx := context.Background()
+	key := cacheKey(network, account)
+
+	if cached, err := streaming.RedisClient.Get(ctx, key).Result(); err == nil {
+		var assessment Assessment
+		if json.Unmarshal([]byte(cached), &assessment) == nil {
+			assessment.Cached = true
+			return assessment, nil
+		}
+	}
+
+	assessment, err := compute(account, network)
+	if err != nil {
+		return assessment, err
+	}
+
+	if assessmentJSON, err := json.Marshal(assessment); err == nil {
+		streaming.RedisClient.Set(ctx, key, assessmentJSON, cacheTTL())
+	}
+	return assessment, nil
+}
+
+func compute(account string, network string) (Assessment, error) {
+	assessment := Assessment{Account: account, Network: network, CheckedAt: time.Now()}
+	add := func(name string, score float64, explanation string) {
+		assessment.Factors = append(assessment.Factors, Factor{Name: name, Score: score, Explanation: explanation})
+	}
+
+	signals, err := loadLocalSignals([]string{account}, network)
+	if err != nil {
+		return assessment, err
+	}
+	addLocalFactors(account, signals[account], add)
+
+	client := streaming.HorizonClient(network)
+	if err := addAccountAgeFactor(client, account, add); err != nil {
+		return assessment, err
+	}
+	if err := addCounterpartyFactor(client, account, network, add); err != nil {
+		return assessment, err
+	}
+
+	assessment.finish()
+	return assessment, nil
+}
+
+// AssessLocal scores account from Fraudy's own data only, without calling
+// Horizon.
+func AssessLocal(account string, network string) (Assessment, error) {
+	assessments, err := AssessLocalBatch([]string{account}, network)
+	return assessments[account], err
+}
+
+// AssessLocalBatch scores accounts like AssessLocal with one grouped query per
+// data source, so it is cheap enough to run on every node of a transaction
+// graph.
+func AssessLocalBatch(accounts []string, network string) (map[string]Assessment, error) {
+	signals, err := loadLocalSignals(accounts, network)
+	if err != nil {
+		return nil, err
+	}
+	assessments := make(map[string]Assessment, len(accounts))
+	for _, account := range accounts {
+		assessment := Assessment{Account: account, Network: network, CheckedAt: time.Now()}
+		addLocalFactors(account, signals[account], func(name string, score float64, explanation string) {
+			assessment.Factors = append(assessment.Factors, Factor{Name: name, Score: score, Explanation: explanation})
+		})
+		assessment.finish()
+		assessments[account] = assessment
+	}
+	return assessments, nil
+}
+
+// finish sums the factor scores and sets the level.
+func (a *Assessment) finish() {
+	for _, factor := range a.Factors {
+		a.Score += factor.Score
+	}
+	a.Score = math.Min(1, a.Score)
+	switch {
+	case a.Score >= 0.7:
+		a.Level = LevelHigh
+	case a.Score >= 0.3:
+		a.Level = LevelMedium
+	default:
+		a.Level = LevelLow
+	}
+	if len(a.Factors) == 0 {
+		a.Factors = append(a.Factors, Factor{Name: "none", Score: 0, Explanation: "No risk signals found"})
+	}
+}
+
+// localSignals is what Fraudy's own data says about an account.
+type localSignals struct {
+	Detections int64 // distinct transactions detected, those judged false positives left out
+	Confirmed  int64 // distinct transactions judged fraud, resolved or not
+	Reputation services.AddressReputation
+}
+
+// loadLocalSignals reads the prior detections on network and community
+// reports of accounts.
+func loadLocalSignals(accounts []string, network string) (map[string]localSignals, error) {
+	signals := make(map[string]localSignals, len(accounts))
+	if len(accounts) == 0 {
+		return signals, nil
+	}
+
+	// Prior detections, counted across all users without exposing their cases.
+	// Every alert watching the account records its own activity, so count
+	// transactions rather than rows.
+	var rows []struct {
+		Account    string
+		Detections int64
+		Confirmed  int64
+	}
+	err := database.DB.Model(&models.FraudActivity{}).
+		Select("account, COUNT(DISTINCT transaction_hash) FILTER (WHERE verdict <> ?) AS detections, "+
+			"COUNT(DISTINCT transaction_hash) FILTER (WHERE verdict = ?) AS confirmed",
+			models.CaseStatusFalsePositive, models.CaseStatusConfirmedFraud).
+		Where("network = ? AND account IN ?", network, accounts).
+		Group("account").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	reputations, err := services.GetAddressReputations(accounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		signals[account] = localSignals{Reputation: reputations[account]}
+	}
+	for _, row := range rows {
+		signal := signals[row.Account]
+		signal.Detections, signal.Confirmed = row.Detections, row.Confirmed
+		signals[row.Account] = signal
+	}
+	return signals, nil
+}
+
+// addLocalFactors adds the operator blocklist, prior detections and community
+// reports.
+func addLocalFactors(account string, signals localSignals, add func(string, float64, string)) {
+	if isOperatorBlocklisted(account) {
+		add("blocklist", 1, "Account is on the operator blocklist")
+	}
+
+	if signals.Confirmed > 0 {
+		add("fraud_activity", 0.6, fmt.Sprintf("Investigators confirmed fraud on %d of %d detections", signals.Confirmed, signals.Detections))
+	} else if signals.Detections > 0 {
+		add("fraud_activity", math.Min(0.4, 0.1*float64(signals.Detections)), fmt.Sprintf("Fraudy detected suspicious activity %d times", signals.Detections))
+	}
+
+	reputation := signals.Reputation
+	if reputation.ConfirmedReports > 0 {
+		add("community_reports", 0.6, fmt.Sprintf("%d community reports were confirmed by moderators", reputation.ConfirmedReports))
+	} else if reputation.Score > 0 {
+		add("community_reports", 0.5*reputation.Score, fmt.Sprintf("%d users filed pending community reports with %d net votes", reputation.PendingReporters, reputation.NetVotes))
+	}
+}
+
+func addAccountAgeFactor(client *horizonclient.Client, account string, add func(string, float64, string)) error {
+	_, err := client.AccountDetail(horizonclient.AccountRequest{AccountID: account})
+	if horizonclient.IsNotFoundError(err) {
+		add("account_age", 0.1, "Account does not exist on the network; a payment would have to create it")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching account from Horizon: %v", err)
+	}
+
+	// The oldest transaction involving the account is the one that created it.
+	page, err := client.Transactions(horizonclient.TransactionRequest{
+		ForAccount: account,
+		Order:      horizonclient.OrderAsc,
+		Limit:      1,
+	})
+	if err != nil {
+		return fmt.Errorf("fetching account history from Horizon: %v", err)
+	}
+	if len(page.Embedded.Records) == 0 {
+		return nil
+	}
+
+	age := time.Since(page.Embedded.Records[0].LedgerCloseTime)
+	days := int(age.Hours() / 24)
+	switch {
+	case age < 7*24*time.Hour:
+		add("account_age", 0.15, fmt.Sprintf("Account was created %d days ago", days))
+	case age < 30*24*time.Hour:
+		add("account_age", 0.05, fmt.Sprintf("Account was created %d days ago", days))
+	}
+	return nil
+}
+
+// counterparties returns the accounts that sent to or received from account
+// in its most recent payments.
+func counterparties(client *horizonclient.Client, account string) ([]string, error) {
+	page, err := client.Payments(horizonclient.OperationRequest{
+		ForAccount: account,
+		Order:      horizonclient.OrderDesc,
+		Limit:      200,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, record := range page.Embedded.Records {
+		var from, to string
+		switch op := record.(type) {
+		case operations.Payment:
+			from, to = op.From, op.To
+		case operations.PathPayment:
+			from, to = op.From, op.To
+		case operations.PathPaymentStrictSend:
+			from, to = op.From, op.To
+		case operations.CreateAccount:
+			from, to = op.Funder, op.Account
+		case operations.AccountMerge:
+			from, to = op.Account, op.Into
+		}
+		for _, party := range []string{from, to} {
+			if party != "" && party != account {
+				seen[party] = true
+			}
+		}
+	}
+
+	parties := make([]string, 0, len(seen))
+	for party := range seen {
+		parties = append(parties, party)
+	}
+	return parties, nil
+}
+
+func addCounterpartyFactor(client *horizonclient.Client, account string, network string, add func(string, float64, string)) error {
+	parties, err := counterparties(client, account)
+	if horizonclient.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetching payments from Horizon: %v", err)
+	}
+	if len(parties) == 0 {
+		return nil
+	}
+
+	flagged := make(map[string]bool)
+	var detected []string
+	database.DB.Model(&models.FraudActivity{}).
+		Where("network = ? AND account IN ? AND verdict <> ?", network, parties, models.CaseStatusFalsePositive).
+		Distinct().Pluck("account", &detected)
+	var reported []string
+	database.DB.Model(&models.ReportedAddress{}).
+		Where("address IN ? AND status = ?", parties, models.ReportStatusConfirmed).
+		Distinct().Pluck("address", &reported)
+	for _, party := range append(detected, reported...) {
+		flagged[party] = true
+	}
+	for _, party := range parties {
+		if isOperatorBlocklisted(party) {
+			flagged[party] = true
+		}
+	}
+
+	if len(flagged) > 0 {
+		add("counterparty_overlap", math.Min(0.3, 0.1*float64(len(flagged))),
+			fmt.Sprintf("Transacted with %d flagged accounts among %d recent counterparties", len(flagged), len(parties)))
+	}
+	return nil
+}
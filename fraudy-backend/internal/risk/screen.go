@@ -0,0 +1,188 @@
+package risk
+
+import (
+	"fmt"
+
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"fraudy-backend/internal/stellartx"
+	"fraudy-backend/internal/streaming"
+
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+const (
+	DecisionAllow = "allow"
+	DecisionWarn  = "warn"
+	DecisionBlock = "block"
+)
+
+// The high failure rate rule flags a source account when this many of its
+// recent transactions failed.
+const (
+	failureWindow    = 20
+	failureThreshold = 10
+)
+
+// ErrInvalidEnvelope is returned when the submitted XDR cannot be decoded.
+type ErrInvalidEnvelope struct {
+	Reason string
+}
+
+func (e ErrInvalidEnvelope) Error() string {
+	return "invalid transaction envelope: " + e.Reason
+}
+
+// ScreeningReason explains why one address in the transaction raised a warning
+// or a block. Operation is the index of the operation that references the
+// address, or -1 for the transaction source.
+type ScreeningReason struct {
+	Operation int         `json:"operation"`
+	Role      string      `json:"role"` // source, destination, issuer
+	Address   string      `json:"address"`
+	Decision  string      `json:"decision"`
+	Message   string      `json:"message"`
+	Risk      *Assessment `json:"risk,omitempty"`
+}
+
+type ScreeningResult struct {
+	Decision   string            `json:"decision"`
+	Network    string            `json:"network"`
+	Hash       string            `json:"hash"`
+	Source     string            `json:"source"`
+	Operations int               `json:"operations"`
+	Reasons    []ScreeningReason `json:"reasons"`
+}
+
+// Screen decodes a base64 transaction envelope and checks it before it is
+// signed: every destination and asset issuer gets a risk lookup and is checked
+// against the organization's lists, and the source account is run through the
+// detection rules. Nothing is submitted.
+func Screen(envelopeXDR string, network string, organizationID uint) (ScreeningResult, error) {
+	result := ScreeningResult{Decision: DecisionAllow, Network: network, Reasons: []ScreeningReason{}}
+
+	tx, err := stellartx.Decode(envelopeXDR)
+	if err != nil {
+		return result, ErrInvalidEnvelope{Reason: err.Error()}
+	}
+	result.Source = stellartx.BaseAccount(tx.SourceAccount().AccountID)
+	result.Operations = len(tx.Operations())
+	if hash, err := tx.HashHex(streaming.NetworkPassphrase(network)); err == nil {
+		result.Hash = hash
+	}
+
+	client := streaming.HorizonClient(network)
+	reason, err := screenSource(client, result.Source)
+	if err != nil {
+		return result, err
+	}
+	if reason != nil {
+		result.add(*reason)
+	}
+
+	var targets []stellartx.Target
+	var addresses []string
+	for _, target := range stellartx.Targets(tx) {
+		if target.Address != result.Source {
+			targets = append(targets, target)
+			addresses = append(addresses, target.Address)
+		}
+	}
+	allowed, err := services.OrganizationListMatches(organizationID, models.ListKindAllowlist, addresses)
+	if err != nil {
+		return result, err
+	}
+	blocked, err := services.OrganizationListMatches(organizationID, models.ListKindBlocklist, addresses)
+	if err != nil {
+		return result, err
+	}
+
+	// The same address often appears in several operations; assess it once.
+	assessments := make(map[string]Assessment)
+	for _, target := range targets {
+		if _, ok := allowed[target.Address]; ok {
+			continue
+		}
+		if list, ok := blocked[target.Address]; ok {
+			result.add(ScreeningReason{
+				Operation: target.Operation,
+				Role:      target.Role,
+				Address:   target.Address,
+				Decision:  DecisionBlock,
+				Message:   fmt.Sprintf("%s is on blocklist %q", target.Role, list),
+			})
+			continue
+		}
+
+		assessment, seen := assessments[target.Address]
+		if !seen {
+			assessment, err = Assess(target.Address, network)
+			if err != nil {
+				return result, err
+			}
+			assessments[target.Address] = assessment
+		}
+
+		decision := DecisionAllow
+		switch assessment.Level {
+		case LevelHigh:
+			decision = DecisionBlock
+		case LevelMedium:
+			decision = DecisionWarn
+		}
+		if decision == DecisionAllow {
+			continue
+		}
+		result.add(ScreeningReason{
+			Operation: target.Operation,
+			Role:      target.Role,
+			Address:   target.Address,
+			Decision:  decision,
+			Message:   fmt.Sprintf("%s has %s risk (score %.2f)", target.Role, assessment.Level, assessment.Score),
+			Risk:      &assessment,
+		})
+	}
+	return result, nil
+}
+
+// add records a reason and raises the overall decision to match it.
+func (r *ScreeningResult) add(reason ScreeningReason) {
+	r.Reasons = append(r.Reasons, reason)
+	if reason.Decision == DecisionBlock || (reason.Decision == DecisionWarn && r.Decision == DecisionAllow) {
+		r.Decision = reason.Decision
+	}
+}
+
+// screenSource applies the high failure rate rule to the account that would
+// submit the transaction.
+func screenSource(client *horizonclient.Client, source string) (*ScreeningReason, error) {
+	page, err := client.Transactions(horizonclient.TransactionRequest{
+		ForAccount:    source,
+		Order:         horizonclient.OrderDesc,
+		Limit:         failureWindow,
+		IncludeFailed: true,
+	})
+	if horizonclient.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching source history from Horizon: %v", err)
+	}
+
+	failures := 0
+	for _, record := range page.Embedded.Records {
+		if !record.Successful {
+			failures++
+		}
+	}
+	if failures < failureThreshold {
+		return nil, nil
+	}
+	return &ScreeningReason{
+		Operation: -1,
+		Role:      "source",
+		Address:   source,
+		Decision:  DecisionWarn,
+		Message:   fmt.Sprintf("highFailureRate: %d of the last %d transactions failed", failures, len(page.Embedded.Records)),
+	}, nil
+}
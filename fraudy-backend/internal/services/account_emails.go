@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	verifyEmailTokenTTL   = 48 * time.Hour
+	passwordResetTokenTTL = time.Hour
+	passwordResetInterval = time.Minute // minimum time between reset emails to one user
+)
+
+var (
+	ErrInvalidAccountToken   = errors.New("invalid, expired or already used token")
+	ErrAccountEmailThrottled = errors.New("an email was sent moments ago")
+)
+
+// AccountEmail is what verification and password reset emails render with.
+type AccountEmail struct {
+	Username  string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// appURL is the frontend that account email links point to, from APP_URL.
+func appURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return "http://localhost:5173"
+}
+
+// IssueAccountToken creates a token for purpose and invalidates the user's
+// earlier unused ones, so only the most recent email works.
+func IssueAccountToken(userID int, purpose string, ttl time.Duration) (string, time.Time, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.AccountToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.AccountToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hashSecretToken(token),
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+	return token, expiresAt, err
+}
+
+// ConsumeAccountToken marks a token for purpose as used and returns it. A
+// token can be consumed once, before it expires.
+func ConsumeAccountToken(token string, purpose string) (models.AccountToken, error) {
+	var accountToken models.AccountToken
+	err := database.DB.Where("token_hash = ? AND purpose = ?", hashSecretToken(token), purpose).First(&accountToken).Error
+	if err != nil || accountToken.UsedAt != nil || time.Now().After(accountToken.ExpiresAt) {
+		return accountToken, ErrInvalidAccountToken
+	}
+
+	// Guard on used_at so two requests with the same token cannot both win.
+	result := database.DB.Model(&models.AccountToken{}).
+		Where("id = ? AND used_at IS NULL", accountToken.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return accountToken, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return accountToken, ErrInvalidAccountToken
+	}
+	return accountToken, nil
+}
+
+// sendAccountEmail emails user a link to path carrying a new token for purpose.
+func sendAccountEmail(user models.User, purpose string, eventType string, path string, ttl time.Duration) error {
+	token, expiresAt, err := IssueAccountToken(int(user.ID), purpose, ttl)
+	if err != nil {
+		return fmt.Errorf("❌ Error issuing %s token: %v", purpose, err)
+	}
+
+	data := NewNotificationData(models.Alert{})
+	data.Account = &AccountEmail{
+		Username:  user.Username,
+		Link:      fmt.Sprintf("%s%s?token=%s", appURL(), path, url.QueryEscape(token)),
+		ExpiresAt: expiresAt,
+	}
+	rendered, err := RenderNotification(0, "email", eventType, data)
+	if err != nil {
+		return err
+	}
+	return SendSystemEmail(user.Email, rendered)
+}
+
+// SendVerificationEmail emails user a link that verifies their address.
+func SendVerificationEmail(user models.User) error {
+	return sendAccountEmail(user, models.TokenPurposeVerifyEmail, EventVerifyEmail, "/verify-email", verifyEmailTokenTTL)
+}
+
+// SendPasswordResetEmail emails user a link to choose a new password. While
+// the link sent less than passwordResetInterval ago is still unused, it sends
+// nothing and returns ErrAccountEmailThrottled, so the endpoint cannot be used
+// to flood an inbox.
+func SendPasswordResetEmail(user models.User) error {
+	var recent int64
+	err := database.DB.Model(&models.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ? AND created_at > ?",
+			user.ID, models.TokenPurposePasswordReset, time.Now(), time.Now().Add(-passwordResetInterval)).
+		Count(&recent).Error
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return ErrAccountEmailThrottled
+	}
+	return sendAccountEmail(user, models.TokenPurposePasswordReset, EventPasswordReset, "/reset-password", passwordResetTokenTTL)
+}
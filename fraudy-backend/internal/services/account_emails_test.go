@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderAccountEmailTemplates(t *testing.T) {
+	data := NewNotificationData(models.Alert{})
+	data.Account = &AccountEmail{
+		Username:  "<analyst>",
+		Link:      "http://localhost:5173/verify-email?token=abc",
+		ExpiresAt: time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC),
+	}
+
+	for _, eventType := range []string{EventVerifyEmail, EventPasswordReset} {
+		source, err := defaultTemplates.ReadFile("templates/email_" + eventType + ".tmpl")
+		require.NoError(t, err)
+		rendered, err := RenderTemplateSource("email", string(source), data)
+		require.NoError(t, err, eventType)
+		assert.NotEmpty(t, rendered.Subject, eventType)
+		assert.Contains(t, rendered.Body, "verify-email?token=abc", eventType)
+		assert.Contains(t, rendered.Body, "&lt;analyst&gt;", eventType)
+		assert.Contains(t, rendered.Body, "2025-03-05 10:30 UTC", eventType)
+	}
+}
+
+func TestSystemMailConfig(t *testing.T) {
+	t.Setenv("SYSTEM_SMTP_SERVER", "")
+	t.Setenv("SYSTEM_EMAIL_SENDER", "")
+	_, err := systemMailConfig("analyst@example.com")
+	assert.Error(t, err)
+
+	t.Setenv("SYSTEM_SMTP_SERVER", "smtp.example.com")
+	t.Setenv("SYSTEM_SMTP_PORT", "")
+	t.Setenv("SYSTEM_EMAIL_SENDER", "no-reply@example.com")
+	config, err := systemMailConfig("analyst@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "email", config.NotificationType)
+	assert.Equal(t, "587", config.SMTPPort)
+	assert.Equal(t, `["analyst@example.com"]`, config.RecipientEmails)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NormalizeListAddress treats anything containing a dot as a domain and
+// anything else as a Stellar account ID.
+func NormalizeListAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.Contains(address, ".") {
+		return NormalizeReportedAddress(models.ReportTypePhishing, address)
+	}
+	return strings.ToUpper(address)
+}
+
+// ParseListEntries reads list entries from a CSV or JSON file.
+//
+// CSV files have the address in the first column and an optional label in the
+// second; a header row starting with "address" is skipped. JSON files hold
+// either an array of addresses or an array of {"address", "label"} objects.
+// An address listed more than once is kept once, with the last label given.
+func ParseListEntries(format string, r io.Reader) ([]models.AddressListEntry, error) {
+	var entries []models.AddressListEntry
+	seen := make(map[string]int)
+	add := func(address string, label string) {
+		if address = NormalizeListAddress(address); address == "" {
+			return
+		}
+		label = strings.TrimSpace(label)
+		if i, ok := seen[address]; ok {
+			entries[i].Label = label
+			return
+		}
+		seen[address] = len(entries)
+		entries = append(entries, models.AddressListEntry{Address: address, Label: label})
+	}
+
+	switch format {
+	case "csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.Comment = '#'
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		for i, record := range records {
+			if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+				continue
+			}
+			label := ""
+			if len(record) > 1 {
+				label = record[1]
+			}
+			add(record[0], label)
+		}
+	case "json":
+		var raw []json.RawMessage
+		if err := json.NewDecoder(r).Decode(&raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON: expected an array: %v", err)
+		}
+		for _, item := range raw {
+			var address string
+			if json.Unmarshal(item, &address) == nil {
+				add(address, "")
+				continue
+			}
+			var entry struct {
+				Address string `json:"address"`
+				Label   string `json:"label"`
+			}
+			if err := json.Unmarshal(item, &entry); err != nil {
+				return nil, fmt.Errorf("invalid JSON entry %s", item)
+			}
+			add(entry.Address, entry.Label)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q, use csv or json", format)
+	}
+	return entries, nil
+}
+
+// ImportListEntries adds entries to a list, updating the label of addresses
+// already on it. With replace set, entries missing from the import are removed.
+func ImportListEntries(listID uint, entries []models.AddressListEntry, replace bool) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if replace {
+			if err := tx.Where("list_id = ?", listID).Delete(&models.AddressListEntry{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		for i := range entries {
+			entries[i].ListID = listID
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "list_id"}, {Name: "address"}},
+			DoUpdates: clause.AssignmentColumns([]string{"label"}),
+		}).CreateInBatches(&entries, 500).Error
+	})
+}
+
+// ListedAddresses returns which of addresses are on the given list.
+func ListedAddresses(listID uint, addresses []string) ([]string, error) {
+	var listed []string
+	if len(addresses) == 0 {
+		return listed, nil
+	}
+	err := database.DB.Model(&models.AddressListEntry{}).
+		Where("list_id = ? AND address IN ?", listID, addresses).
+		Pluck("address", &listed).Error
+	return listed, err
+}
+
+// OrganizationListMatches maps each of addresses found on one of the
+// organization's lists of the given kind to the name of that list.
+func OrganizationListMatches(organizationID uint, kind string, addresses []string) (map[string]string, error) {
+	matches := make(map[string]string)
+	if len(addresses) == 0 {
+		return matches, nil
+	}
+
+	var rows []struct {
+		Address string
+		Name    string
+	}
+	err := database.DB.Model(&models.AddressListEntry{}).
+		Select("address_list_entries.address, address_lists.name").
+		Joins("JOIN address_lists ON address_lists.id = address_list_entries.list_id AND address_lists.deleted_at IS NULL").
+		Where("address_lists.organization_id = ? AND address_lists.kind = ? AND address_list_entries.address IN ?", organizationID, kind, addresses).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		matches[row.Address] = row.Name
+	}
+	return matches, nil
+}
+
+// IsAllowlisted reports whether any of addresses is on one of the
+// organization's allowlists.
+func IsAllowlisted(organizationID uint, addresses ...string) bool {
+	var present []string
+	for _, address := range addresses {
+		if address != "" {
+			present = append(present, address)
+		}
+	}
+	matches, err := OrganizationListMatches(organizationID, models.ListKindAllowlist, present)
+	if err != nil {
+		fmt.Printf("❌ Error checking allowlists for organization %d: %v\n", organizationID, err)
+		return false
+	}
+	return len(matches) > 0
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListEntriesCSV(t *testing.T) {
+	input := "address,label\n" +
+		"gbpuzmfjiuj5zvjr4yij4qa2cirqgazuwoop4uj5k7w7w5eeqrmefljz,Exchange hot wallet\n" +
+		"# comment\n" +
+		"https://www.Fake-DeFi.io/login\n"
+
+	entries, err := ParseListEntries("csv", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ", entries[0].Address)
+	assert.Equal(t, "Exchange hot wallet", entries[0].Label)
+	assert.Equal(t, "fake-defi.io", entries[1].Address)
+}
+
+func TestParseListEntriesDuplicates(t *testing.T) {
+	input := "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ,Old label\n" +
+		"scam.example\n" +
+		"gbpuzmfjiuj5zvjr4yij4qa2cirqgazuwoop4uj5k7w7w5eeqrmefljz,New label\n" +
+		"https://scam.example/login\n"
+
+	entries, err := ParseListEntries("csv", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ", entries[0].Address)
+	assert.Equal(t, "New label", entries[0].Label)
+	assert.Equal(t, "scam.example", entries[1].Address)
+}
+
+func TestParseListEntriesJSON(t *testing.T) {
+	input := `["GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ", {"address": "scam.example", "label": "Phishing"}, ""]`
+
+	entries, err := ParseListEntries("json", strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "scam.example", entries[1].Address)
+	assert.Equal(t, "Phishing", entries[1].Label)
+
+	_, err = ParseListEntries("json", strings.NewReader(`{"address": "scam.example"}`))
+	assert.Error(t, err)
+	_, err = ParseListEntries("xlsx", strings.NewReader(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from access
+// tokens and makes leaked keys easy to search for.
+const APIKeyPrefix = "fraudy_"
+
+// apiKeyTouchInterval limits how often last_used_at is written for a busy key.
+const apiKeyTouchInterval = time.Minute
+
+var ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+
+// CreateAPIKey stores key with a new secret and returns the secret, which is
+// only shown this once.
+func CreateAPIKey(key *models.APIKey) (string, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return "", err
+	}
+	raw := APIKeyPrefix + token
+	key.Prefix = raw[:len(APIKeyPrefix)+6]
+	key.KeyHash = hashSecretToken(raw)
+	if err := database.DB.Create(key).Error; err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// AuthenticateAPIKey returns the active key raw belongs to and records that
+// it was used.
+func AuthenticateAPIKey(raw string) (models.APIKey, error) {
+	var key models.APIKey
+	if err := database.DB.Where("key_hash = ?", hashSecretToken(raw)).First(&key).Error; err != nil {
+		return key, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if !apiKeyUsable(key, now) {
+		return key, ErrInvalidAPIKey
+	}
+
+	database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", key.ID, now.Add(-apiKeyTouchInterval)).
+		Update("last_used_at", now)
+	return key, nil
+}
+
+// apiKeyUsable reports whether key is neither revoked nor expired at now.
+func apiKeyUsable(key models.APIKey, now time.Time) bool {
+	return key.RevokedAt == nil && (key.ExpiresAt == nil || !now.After(*key.ExpiresAt))
+}
+
+// APIKeyScopes splits the stored scopes of key.
+func APIKeyScopes(key models.APIKey) []string {
+	if key.Scopes == "" {
+		return nil
+	}
+	return strings.Split(key.Scopes, ",")
+}
+
+// UserAPIKeys lists the personal keys of userID that are not revoked.
+func UserAPIKeys(userID int) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := database.DB.Where("user_id = ? AND organization_id = 0 AND revoked_at IS NULL", userID).
+		Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// OrganizationAPIKeys lists the organization keys of organizationID that are
+// not revoked.
+func OrganizationAPIKeys(organizationID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := database.DB.Where("organization_id = ? AND revoked_at IS NULL", organizationID).
+		Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey stops key working and reports whether it was active.
+func RevokeAPIKey(keyID uint) (bool, error) {
+	result := database.DB.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", keyID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyScopes(t *testing.T) {
+	assert.Nil(t, APIKeyScopes(models.APIKey{}))
+	assert.Equal(t, []string{"activities:read", "transactions:screen"},
+		APIKeyScopes(models.APIKey{Scopes: "activities:read,transactions:screen"}))
+}
+
+func TestAPIKeyUsable(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+
+	assert.True(t, apiKeyUsable(models.APIKey{}, now))
+	assert.True(t, apiKeyUsable(models.APIKey{ExpiresAt: &later}, now))
+	assert.False(t, apiKeyUsable(models.APIKey{ExpiresAt: &earlier}, now), "expired")
+	assert.False(t, apiKeyUsable(models.APIKey{RevokedAt: &earlier}, now), "revoked")
+	assert.False(t, apiKeyUsable(models.APIKey{RevokedAt: &earlier, ExpiresAt: &later}, now), "revoked before expiry")
+}
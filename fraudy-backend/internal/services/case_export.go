@@ -0,0 +1,236 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var caseCSVHeader = []string{
+	"id", "created_at", "account", "type", "flag", "status", "network", "alert_id", "alert_name",
+	"counterparty", "transactions", "failure_count", "assignee_id", "resolution_notes",
+	"comments", "status_changes", "notifications",
+}
+
+var timelineCSVHeader = []string{"at", "kind", "actor", "detail"}
+
+// csvSafe keeps spreadsheets from running a cell as a formula: values starting
+// with =, +, -, @, a tab or a carriage return are prefixed with a quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// writeCSVRow writes cells with csvSafe applied to each.
+func writeCSVRow(w *csv.Writer, cells []string) error {
+	for i, cell := range cells {
+		cells[i] = csvSafe(cell)
+	}
+	return w.Write(cells)
+}
+
+// WriteCaseCSVHeader writes the header matching WriteCasesCSV rows.
+func WriteCaseCSVHeader(w *csv.Writer) error {
+	return w.Write(caseCSVHeader)
+}
+
+// WriteCasesCSV writes one row per case. Transactions are separated by
+// spaces; comments, status changes and notifications are counted. Cells are
+// escaped with csvSafe.
+func WriteCasesCSV(w *csv.Writer, reports []CaseReport) error {
+	for _, report := range reports {
+		activity := report.Activity
+		alertName, assignee := "", ""
+		if report.Alert != nil {
+			alertName = report.Alert.AlertName
+		}
+		if activity.AssigneeID != nil {
+			assignee = strconv.FormatUint(uint64(*activity.AssigneeID), 10)
+		}
+		err := writeCSVRow(w, []string{
+			strconv.FormatUint(uint64(activity.ID), 10),
+			activity.CreatedAt.UTC().Format(time.RFC3339),
+			activity.Account,
+			activity.Type,
+			activity.Flag,
+			activity.Status,
+			activity.Network,
+			strconv.FormatUint(uint64(activity.AlertID), 10),
+			alertName,
+			activity.Counterparty,
+			strings.Join(report.Transactions, " "),
+			strconv.Itoa(activity.FailureCount),
+			assignee,
+			activity.ResolutionNotes,
+			strconv.Itoa(len(report.Comments)),
+			strconv.Itoa(len(report.StatusHistory)),
+			strconv.Itoa(len(report.Deliveries)),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTimelineCSV writes the timeline of a single case, one event per row.
+// Cells are escaped with csvSafe.
+func WriteTimelineCSV(w *csv.Writer, report CaseReport) error {
+	if err := w.Write(timelineCSVHeader); err != nil {
+		return err
+	}
+	for _, entry := range report.Timeline {
+		if err := writeCSVRow(w, []string{entry.At.UTC().Format(time.RFC3339), entry.Kind, entry.Actor, entry.Detail}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCasesNDJSON writes each full case report as one JSON line.
+func WriteCasesNDJSON(w io.Writer, reports []CaseReport) error {
+	encoder := json.NewEncoder(w)
+	for _, report := range reports {
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCasesPDF renders an evidence report with a section per case covering
+// its details, transactions, timeline, comments and notification history.
+func WriteCasesPDF(w io.Writer, title string, reports []CaseReport) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	generatedAt := time.Now().UTC().Format("2006-01-02 15:04 MST")
+
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Fraudy", true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 10, tr(fmt.Sprintf("%s - generated %s - page %d/{nb}", title, generatedAt, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+
+	heading := func(size float64, text string) {
+		pdf.SetFont("Helvetica", "B", size)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.MultiCell(0, size*0.5, tr(text), "", "L", false)
+		pdf.Ln(1)
+	}
+	field := func(label string, value string) {
+		if value == "" {
+			return
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(40, 5, tr(label), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(value), "", "L", false)
+	}
+	text := func(value string) {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, tr(value), "", "L", false)
+	}
+	row := func(widths []float64, values []string, header bool) {
+		style := ""
+		if header {
+			style = "B"
+			pdf.SetFillColor(230, 230, 230)
+		}
+		pdf.SetFont("Helvetica", style, 8)
+		for i, value := range values {
+			pdf.CellFormat(widths[i], 6, tr(truncate(value, int(widths[i]*0.55))), "1", 0, "L", header, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	heading(16, title)
+	text(fmt.Sprintf("Generated %s. %d case(s).", generatedAt, len(reports)))
+	pdf.Ln(4)
+
+	for i, report := range reports {
+		activity := report.Activity
+		if i > 0 {
+			pdf.AddPage()
+		}
+		heading(13, fmt.Sprintf("Case #%d - %s", activity.ID, activity.Type))
+		field("Account", activity.Account)
+		field("Counterparty", activity.Counterparty)
+		field("Network", activity.Network)
+		field("Flag", activity.Flag)
+		field("Status", activity.Status)
+		field("Detected", activity.CreatedAt.UTC().Format(time.RFC3339))
+		if report.Alert != nil {
+			field("Alert", fmt.Sprintf("%s (#%d, %s on %s)", report.Alert.AlertName, report.Alert.ID, report.Alert.RuleType, report.Alert.WalletID))
+		}
+		if activity.FailureCount > 0 {
+			field("Failures", strconv.Itoa(activity.FailureCount))
+		}
+		field("Resolution", activity.ResolutionNotes)
+		pdf.Ln(3)
+
+		heading(11, "Transactions")
+		if len(report.Transactions) == 0 {
+			text("None recorded.")
+		}
+		for _, hash := range report.Transactions {
+			pdf.SetFont("Courier", "", 8)
+			pdf.CellFormat(0, 5, hash, "", 1, "L", false, 0, "")
+		}
+		pdf.Ln(3)
+
+		heading(11, "Timeline")
+		widths := []float64{38, 26, 40, 86}
+		row(widths, []string{"Time (UTC)", "Event", "By", "Details"}, true)
+		for _, entry := range report.Timeline {
+			row(widths, []string{entry.At.UTC().Format("2006-01-02 15:04:05"), entry.Kind, entry.Actor, entry.Detail}, false)
+		}
+		pdf.Ln(3)
+
+		heading(11, "Comments")
+		if len(report.Comments) == 0 {
+			text("No comments.")
+		}
+		for _, comment := range report.Comments {
+			pdf.SetFont("Helvetica", "B", 8)
+			pdf.CellFormat(0, 5, tr(fmt.Sprintf("%s - %s", comment.CreatedAt.UTC().Format("2006-01-02 15:04:05"), report.Actors[comment.UserID])), "", 1, "L", false, 0, "")
+			text(comment.Body)
+			pdf.Ln(1)
+		}
+		pdf.Ln(3)
+
+		heading(11, "Notification history")
+		if len(report.Deliveries) == 0 {
+			text("No notifications were attempted.")
+		} else {
+			widths := []float64{38, 26, 26, 100}
+			row(widths, []string{"Time (UTC)", "Channel", "Status", "Error"}, true)
+			for _, delivery := range report.Deliveries {
+				row(widths, []string{delivery.CreatedAt.UTC().Format("2006-01-02 15:04:05"), delivery.Channel, delivery.Status, delivery.Error}, false)
+			}
+		}
+	}
+
+	return pdf.Output(w)
+}
+
+// truncate shortens s to at most n characters for fixed-width table cells.
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
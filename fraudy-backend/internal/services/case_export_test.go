@@ -0,0 +1,82 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleCaseReport() CaseReport {
+	activity := models.FraudActivity{
+		Account:           "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ",
+		Type:              "highFailureRate",
+		TransactionHashes: `["aa","bb"]`,
+		Flag:              "Medium",
+		Status:            models.CaseStatusResolved,
+		ResolutionNotes:   "Bot retrying with a stale sequence number",
+	}
+	activity.ID = 7
+	activity.CreatedAt = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	comment := models.FraudActivityComment{FraudActivityID: 7, UserID: 1, Body: "Checked with the wallet owner – known bot."}
+	comment.CreatedAt = activity.CreatedAt.Add(time.Hour)
+
+	report := CaseReport{
+		Activity:     activity,
+		Transactions: []string{"aa", "bb"},
+		Comments:     []models.FraudActivityComment{comment},
+		Actors:       map[int]string{1: "analyst@example.com"},
+	}
+	report.Timeline = buildTimeline(report, report.Actors)
+	return report
+}
+
+func TestWriteCaseExports(t *testing.T) {
+	report := sampleCaseReport()
+	require.Len(t, report.Timeline, 2)
+	assert.Equal(t, "detected", report.Timeline[0].Kind)
+	assert.Equal(t, "analyst@example.com", report.Timeline[1].Actor)
+
+	var csvOut bytes.Buffer
+	w := csv.NewWriter(&csvOut)
+	require.NoError(t, WriteCaseCSVHeader(w))
+	require.NoError(t, WriteCasesCSV(w, []CaseReport{report}))
+	w.Flush()
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "aa bb", rows[1][10])
+
+	var ndjson bytes.Buffer
+	require.NoError(t, WriteCasesNDJSON(&ndjson, []CaseReport{report, report}))
+	assert.Equal(t, 2, strings.Count(ndjson.String(), "\n"))
+
+	var pdf bytes.Buffer
+	require.NoError(t, WriteCasesPDF(&pdf, "Case #7 Evidence Report", []CaseReport{report}))
+	assert.True(t, bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-")))
+}
+
+func TestCSVSafe(t *testing.T) {
+	for _, value := range []string{"=HYPERLINK(\"http://evil\")", "+1", "-2+3", "@SUM(A1)", "\tx", "\rx"} {
+		assert.Equal(t, "'"+value, csvSafe(value))
+	}
+	assert.Equal(t, "GBPUZ", csvSafe("GBPUZ"))
+	assert.Equal(t, "", csvSafe(""))
+
+	report := sampleCaseReport()
+	report.Activity.ResolutionNotes = "=cmd|' /C calc'!A0"
+	var csvOut bytes.Buffer
+	w := csv.NewWriter(&csvOut)
+	require.NoError(t, WriteCasesCSV(w, []CaseReport{report}))
+	w.Flush()
+	rows, err := csv.NewReader(&csvOut).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, "'=cmd|' /C calc'!A0", rows[0][13])
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+// TimelineEntry is one dated event in the life of a case.
+type TimelineEntry struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"` // detected, status_change, comment, notification
+	Actor  string    `json:"actor,omitempty"`
+	Detail string    `json:"detail"`
+}
+
+// CaseReport is everything compliance needs about one fraud activity.
+type CaseReport struct {
+	Activity      models.FraudActivity               `json:"activity"`
+	Alert         *models.Alert                      `json:"alert,omitempty"`
+	Transactions  []string                           `json:"transactions"`
+	Comments      []models.FraudActivityComment      `json:"comments"`
+	StatusHistory []models.FraudActivityStatusChange `json:"status_history"`
+	Deliveries    []models.NotificationDelivery      `json:"notification_history"`
+	Timeline      []TimelineEntry                    `json:"timeline"`
+	Actors        map[int]string                     `json:"actors"` // emails of the users who commented or changed status
+}
+
+// LoadCaseReports gathers the alert, comments, status history and
+// notification history of each activity with one query per kind.
+func LoadCaseReports(activities []models.FraudActivity) ([]CaseReport, error) {
+	reports := make([]CaseReport, len(activities))
+	if len(activities) == 0 {
+		return reports, nil
+	}
+
+	activityIDs := make([]uint, len(activities))
+	alertIDs := make([]uint, 0, len(activities))
+	for i, activity := range activities {
+		activityIDs[i] = activity.ID
+		alertIDs = append(alertIDs, activity.AlertID)
+	}
+
+	var alerts []models.Alert
+	var comments []models.FraudActivityComment
+	var changes []models.FraudActivityStatusChange
+	var deliveries []models.NotificationDelivery
+	for _, load := range []struct {
+		dest  interface{}
+		query string
+		ids   interface{}
+	}{
+		{&alerts, "id IN ?", alertIDs},
+		{&comments, "fraud_activity_id IN ?", activityIDs},
+		{&changes, "fraud_activity_id IN ?", activityIDs},
+		{&deliveries, "fraud_activity_id IN ?", activityIDs},
+	} {
+		if err := database.DB.Where(load.query, load.ids).Order("created_at ASC").Find(load.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	userIDs := make(map[int]bool)
+	for _, comment := range comments {
+		userIDs[comment.UserID] = true
+	}
+	for _, change := range changes {
+		userIDs[change.UserID] = true
+	}
+	actors, err := userEmails(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	alertsByID := make(map[uint]*models.Alert)
+	for i := range alerts {
+		alertsByID[alerts[i].ID] = &alerts[i]
+	}
+	index := make(map[uint]int)
+	for i, activity := range activities {
+		index[activity.ID] = i
+		reports[i] = CaseReport{
+			Activity:      activity,
+			Alert:         alertsByID[activity.AlertID],
+			Transactions:  []string{},
+			Comments:      []models.FraudActivityComment{},
+			StatusHistory: []models.FraudActivityStatusChange{},
+			Deliveries:    []models.NotificationDelivery{},
+		}
+		json.Unmarshal([]byte(activity.TransactionHashes), &reports[i].Transactions)
+		if len(reports[i].Transactions) == 0 && activity.TransactionHash != "" {
+			reports[i].Transactions = []string{activity.TransactionHash}
+		}
+	}
+	for _, comment := range comments {
+		report := &reports[index[comment.FraudActivityID]]
+		report.Comments = append(report.Comments, comment)
+	}
+	for _, change := range changes {
+		report := &reports[index[change.FraudActivityID]]
+		report.StatusHistory = append(report.StatusHistory, change)
+	}
+	for _, delivery := range deliveries {
+		report := &reports[index[delivery.FraudActivityID]]
+		report.Deliveries = append(report.Deliveries, delivery)
+	}
+
+	for i := range reports {
+		reports[i].Actors = actors
+		reports[i].Timeline = buildTimeline(reports[i], actors)
+	}
+	return reports, nil
+}
+
+func userEmails(ids map[int]bool) (map[int]string, error) {
+	emails := make(map[int]string)
+	if len(ids) == 0 {
+		return emails, nil
+	}
+	list := make([]int, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	var users []models.User
+	if err := database.DB.Select("id, email").Where("id IN ?", list).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		emails[int(user.ID)] = user.Email
+	}
+	return emails, nil
+}
+
+func buildTimeline(report CaseReport, actors map[int]string) []TimelineEntry {
+	activity := report.Activity
+	timeline := []TimelineEntry{{
+		At:     activity.CreatedAt,
+		Kind:   "detected",
+		Detail: fmt.Sprintf("%s detected on %s (flag %s)", activity.Type, activity.Account, activity.Flag),
+	}}
+	for _, change := range report.StatusHistory {
+		detail := fmt.Sprintf("%s -> %s", change.FromStatus, change.ToStatus)
+		if change.Note != "" {
+			detail += ": " + change.Note
+		}
+		timeline = append(timeline, TimelineEntry{At: change.CreatedAt, Kind: "status_change", Actor: actors[change.UserID], Detail: detail})
+	}
+	for _, comment := range report.Comments {
+		timeline = append(timeline, TimelineEntry{At: comment.CreatedAt, Kind: "comment", Actor: actors[comment.UserID], Detail: comment.Body})
+	}
+	for _, delivery := range report.Deliveries {
+		detail := fmt.Sprintf("%s notification %s", delivery.Channel, delivery.Status)
+		if delivery.Error != "" {
+			detail += ": " + delivery.Error
+		}
+		timeline = append(timeline, TimelineEntry{At: delivery.CreatedAt, Kind: "notification", Detail: detail})
+	}
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].At.Before(timeline[j].At) })
+	return timeline
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DeliverySent       = "sent"
+	DeliveryFailed     = "failed"
+	DeliverySuppressed = "suppressed"
+	DeliveryThrottled  = "throttled"
+	DeliveryQueued     = "queued"
+	DeliverySending    = "sending" // claimed by a digest run that is sending it
+)
+
+const defaultDigestInterval = 60 // minutes
+
+// alertChannels returns the lowercased channels selected on an alert,
+// falling back to email for alerts created without preferences.
+func alertChannels(alert models.Alert) []string {
+	var preferences []string
+	if err := json.Unmarshal([]byte(alert.NotificationPreferences), &preferences); err != nil || len(preferences) == 0 {
+		return []string{"email"}
+	}
+
+	channels := make([]string, 0, len(preferences))
+	for _, preference := range preferences {
+		channels = append(channels, strings.ToLower(preference))
+	}
+	return channels
+}
+
+// GetNotificationPolicy returns the policy for an alert and channel. Alerts
+// without a stored policy notify on every trigger.
+func GetNotificationPolicy(alertID uint, channel string) models.NotificationPolicy {
+	policy := models.NotificationPolicy{AlertID: alertID, Channel: channel}
+	database.DB.Where("alert_id = ? AND channel = ?", alertID, channel).First(&policy)
+	return policy
+}
+
+func recordDelivery(alert models.Alert, channel string, fraudID uint, status string, deliveryErr error) {
+	delivery := models.NotificationDelivery{
+		AlertID:         alert.ID,
+		UserID:          alert.UserID,
+		OrganizationID:  alert.OrganizationID,
+		Channel:         channel,
+		FraudActivityID: fraudID,
+		Status:          status,
+	}
+	if deliveryErr != nil {
+		delivery.Error = deliveryErr.Error()
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		fmt.Println("❌ Error recording notification delivery:", err)
+	}
+}
+
+func countDeliveriesSince(alertID uint, channel string, since time.Time) int64 {
+	var count int64
+	database.DB.Model(&models.NotificationDelivery{}).
+		Where("alert_id = ? AND channel = ? AND status = ? AND created_at > ?", alertID, channel, DeliverySent, since).
+		Count(&count)
+	return count
+}
+
+func sendAlertNotification(alert models.Alert, channel string, fraud models.FraudActivity) error {
+	rendered, err := RenderNotification(alert.OrganizationID, channel, EventAlert, NewNotificationData(alert, fraud))
+	if err != nil {
+		return err
+	}
+	return SendNotification(alert.OrganizationID, channel, rendered)
+}
+
+func sendDigestNotification(alert models.Alert, channel string, activities []models.FraudActivity) error {
+	rendered, err := RenderNotification(alert.OrganizationID, channel, EventDigest, NewNotificationData(alert, activities...))
+	if err != nil {
+		return err
+	}
+	return SendNotification(alert.OrganizationID, channel, rendered)
+}
+
+// NotifyAlert delivers a triggered alert through each of its channels,
+// applying the suppression window, hourly limit and digest mode of the
+// channel's policy.
+func NotifyAlert(alert models.Alert, fraud models.FraudActivity) {
+	for _, channel := range alertChannels(alert) {
+		policy := GetNotificationPolicy(alert.ID, channel)
+
+		if policy.DigestMode {
+			fmt.Printf("📥 Queued %s notification for digest (Alert: %d)\n", channel, alert.ID)
+			recordDelivery(alert, channel, fraud.ID, DeliveryQueued, nil)
+			continue
+		}
+
+		if policy.SuppressionWindow > 0 {
+			since := time.Now().Add(-time.Duration(policy.SuppressionWindow) * time.Minute)
+			if countDeliveriesSince(alert.ID, channel, since) > 0 {
+				fmt.Printf("🔕 Suppressed duplicate %s notification (Alert: %d)\n", channel, alert.ID)
+				recordDelivery(alert, channel, fraud.ID, DeliverySuppressed, nil)
+				continue
+			}
+		}
+
+		if policy.MaxPerHour > 0 {
+			if countDeliveriesSince(alert.ID, channel, time.Now().Add(-time.Hour)) >= int64(policy.MaxPerHour) {
+				fmt.Printf("⏳ Hourly %s notification limit reached (Alert: %d)\n", channel, alert.ID)
+				recordDelivery(alert, channel, fraud.ID, DeliveryThrottled, nil)
+				continue
+			}
+		}
+
+		if err := sendAlertNotification(alert, channel, fraud); err != nil {
+			fmt.Printf("❌ Failed to send %s notification: %v\n", channel, err)
+			recordDelivery(alert, channel, fraud.ID, DeliveryFailed, err)
+			continue
+		}
+		recordDelivery(alert, channel, fraud.ID, DeliverySent, nil)
+	}
+}
+
+// staleSendingAfter is how long a digest delivery can stay claimed before it
+// is taken to belong to a run that died, far longer than a send can take.
+const staleSendingAfter = 15 * time.Minute
+
+// requeueStaleDeliveries returns deliveries left in sending by a run that
+// stopped before recording the outcome to the queue, so the next digest sends
+// them rather than dropping them.
+func requeueStaleDeliveries() {
+	result := database.DB.Model(&models.NotificationDelivery{}).
+		Where("status = ? AND updated_at < ?", DeliverySending, time.Now().Add(-staleSendingAfter)).
+		Update("status", DeliveryQueued)
+	if result.Error != nil {
+		fmt.Println("❌ Error requeueing stale digest deliveries:", result.Error)
+	} else if result.RowsAffected > 0 {
+		fmt.Printf("♻️ Requeued %d digest deliveries stuck in sending\n", result.RowsAffected)
+	}
+}
+
+// ProcessNotificationDigests sends one summary per digest policy once the
+// oldest queued notification is older than the policy's digest interval.
+// Queued deliveries are claimed before sending, so instances running the
+// worker side by side never send the same notification twice. Claims older
+// than staleSendingAfter are requeued first.
+func ProcessNotificationDigests() {
+	requeueStaleDeliveries()
+
+	var policies []models.NotificationPolicy
+	if err := database.DB.Where("digest_mode = ?", true).Find(&policies).Error; err != nil {
+		fmt.Println("❌ Error fetching digest policies:", err)
+		return
+	}
+
+	for _, policy := range policies {
+		var queued []models.NotificationDelivery
+		database.DB.Where("alert_id = ? AND channel = ? AND status = ?", policy.AlertID, policy.Channel, DeliveryQueued).
+			Order("created_at ASC").Find(&queued)
+		if len(queued) == 0 {
+			continue
+		}
+
+		interval := policy.DigestInterval
+		if interval <= 0 {
+			interval = defaultDigestInterval
+		}
+		if time.Since(queued[0].CreatedAt) < time.Duration(interval)*time.Minute {
+			continue
+		}
+
+		var alert models.Alert
+		if err := database.DB.First(&alert, policy.AlertID).Error; err != nil {
+			fmt.Printf("⚠️ Alert %d for digest policy not found: %v\n", policy.AlertID, err)
+			continue
+		}
+
+		deliveryIDs := make([]uint, 0, len(queued))
+		for _, delivery := range queued {
+			deliveryIDs = append(deliveryIDs, delivery.ID)
+		}
+
+		// Only the rows this run moved out of queued are its to send.
+		var claimed []models.NotificationDelivery
+		err := database.DB.Model(&claimed).Clauses(clause.Returning{}).
+			Where("id IN ? AND status = ?", deliveryIDs, DeliveryQueued).
+			Update("status", DeliverySending).Error
+		if err != nil {
+			fmt.Printf("❌ Error claiming %s digest (Alert: %d): %v\n", policy.Channel, alert.ID, err)
+			continue
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+		deliveryIDs = deliveryIDs[:0]
+		fraudIDs := make([]uint, 0, len(claimed))
+		for _, delivery := range claimed {
+			deliveryIDs = append(deliveryIDs, delivery.ID)
+			fraudIDs = append(fraudIDs, delivery.FraudActivityID)
+		}
+
+		var activities []models.FraudActivity
+		database.DB.Where("id IN ?", fraudIDs).Order("created_at ASC").Find(&activities)
+
+		updates := map[string]interface{}{"status": DeliverySent}
+		if err := sendDigestNotification(alert, policy.Channel, activities); err != nil {
+			fmt.Printf("❌ Failed to send %s digest: %v\n", policy.Channel, err)
+			updates = map[string]interface{}{"status": DeliveryFailed, "error": err.Error()}
+		} else {
+			fmt.Printf("✅ Sent %s digest with %d activities (Alert: %d)\n", policy.Channel, len(activities), alert.ID)
+		}
+		database.DB.Model(&models.NotificationDelivery{}).Where("id IN ?", deliveryIDs).Updates(updates)
+	}
+}
+
+func RunDigestWorker(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ProcessNotificationDigests()
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BackfillFraudActivityOwners links activities recorded before they carried an
+// owner to the alerts that watch their account, network and rule. An activity
+// matching several alerts is copied so that every alert owner gets their own
+// row. Each activity is linked and copied atomically. It runs within tx, see
+// RunStartupBackfills.
+func BackfillFraudActivityOwners(tx *gorm.DB) error {
+	var orphans []models.FraudActivity
+	if err := tx.Where("alert_id IS NULL OR alert_id = 0").Find(&orphans).Error; err != nil {
+		return fmt.Errorf("❌ Error fetching unowned fraud activities: %v", err)
+	}
+
+	for _, activity := range orphans {
+		var alerts []models.Alert
+		tx.Where("wallet_id = ? AND network = ? AND rule_type = ?", activity.Account, activity.Network, activity.Type).
+			Order("id ASC").Find(&alerts)
+		if len(alerts) == 0 {
+			continue
+		}
+
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			for i, alert := range alerts {
+				if i == 0 {
+					err := tx.Model(&activity).Updates(map[string]interface{}{"alert_id": alert.ID, "user_id": alert.UserID, "organization_id": alert.OrganizationID}).Error
+					if err != nil {
+						return err
+					}
+					continue
+				}
+				copied := activity
+				copied.ID = 0
+				copied.AlertID = alert.ID
+				copied.UserID = alert.UserID
+				copied.OrganizationID = alert.OrganizationID
+				if err := tx.Create(&copied).Error; err != nil {
+					return fmt.Errorf("copying for alert %d: %v", alert.ID, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("❌ Error backfilling fraud activity %d: %v", activity.ID, err)
+		}
+	}
+
+	fmt.Printf("✅ Checked %d unowned fraud activities\n", len(orphans))
+	return nil
+}
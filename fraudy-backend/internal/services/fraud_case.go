@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// caseTransitions lists the statuses each case status may move to.
+// Resolved cases can only be reopened for further investigation.
+var caseTransitions = map[string][]string{
+	models.CaseStatusNew:            {models.CaseStatusAcknowledged, models.CaseStatusInvestigating, models.CaseStatusFalsePositive},
+	models.CaseStatusAcknowledged:   {models.CaseStatusInvestigating, models.CaseStatusConfirmedFraud, models.CaseStatusFalsePositive},
+	models.CaseStatusInvestigating:  {models.CaseStatusConfirmedFraud, models.CaseStatusFalsePositive},
+	models.CaseStatusConfirmedFraud: {models.CaseStatusResolved},
+	models.CaseStatusFalsePositive:  {models.CaseStatusResolved},
+	models.CaseStatusResolved:       {models.CaseStatusInvestigating},
+}
+
+// ErrInvalidTransition is returned when a case cannot move to the requested status.
+type ErrInvalidTransition struct {
+	From string
+	To   string
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("cannot move case from %s to %s", e.From, e.To)
+}
+
+// CanTransitionCase reports whether a case in status from may move to status to.
+func CanTransitionCase(from string, to string) bool {
+	for _, allowed := range caseTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// caseUpdates returns the columns a move to status to changes. The verdict is
+// set when a case is confirmed or dismissed and kept when it is resolved, so
+// risk scoring still knows how a closed case ended. Reopening a case clears
+// its verdict and resolution note, which stay in the history.
+func caseUpdates(to string, note string) map[string]interface{} {
+	updates := map[string]interface{}{"status": to}
+	switch {
+	case to == models.CaseStatusConfirmedFraud || to == models.CaseStatusFalsePositive:
+		updates["verdict"] = to
+	case to == models.CaseStatusResolved:
+		updates["resolution_notes"] = note
+	case slices.Contains(openCaseStatuses, to):
+		updates["verdict"] = ""
+		updates["resolution_notes"] = ""
+	}
+	return updates
+}
+
+// TransitionFraudActivity moves a case to a new status and appends the change
+// to its history. Resolving a case requires a resolution note. See caseUpdates
+// for the columns that change with the status.
+func TransitionFraudActivity(activity *models.FraudActivity, userID int, to string, note string) error {
+	from := activity.Status
+	if !CanTransitionCase(from, to) {
+		return ErrInvalidTransition{From: from, To: to}
+	}
+	if to == models.CaseStatusResolved && note == "" {
+		return fmt.Errorf("a resolution note is required to resolve a case")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := caseUpdates(to, note)
+		// Guard on the current status so concurrent transitions cannot both win.
+		result := tx.Model(&models.FraudActivity{}).Where("id = ? AND status = ?", activity.ID, from).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidTransition{From: from, To: to}
+		}
+
+		change := models.FraudActivityStatusChange{
+			FraudActivityID: activity.ID,
+			UserID:          userID,
+			FromStatus:      from,
+			ToStatus:        to,
+			Note:            note,
+		}
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
+		activity.Status = to
+		if verdict, ok := updates["verdict"].(string); ok {
+			activity.Verdict = verdict
+		}
+		if notes, ok := updates["resolution_notes"].(string); ok {
+			activity.ResolutionNotes = notes
+		}
+		return nil
+	})
+}
+
+// BackfillCaseVerdicts sets the verdict of cases decided before verdicts were
+// stored: from their status, or for resolved cases from the last decision in
+// their history. It runs within tx, see RunStartupBackfills.
+func BackfillCaseVerdicts(tx *gorm.DB) error {
+	verdicts := []string{models.CaseStatusConfirmedFraud, models.CaseStatusFalsePositive}
+	err := tx.Model(&models.FraudActivity{}).Where("verdict = '' AND status IN ?", verdicts).
+		Update("verdict", gorm.Expr("status")).Error
+	if err != nil {
+		return fmt.Errorf("❌ Error backfilling case verdicts: %v", err)
+	}
+	err = tx.Exec(`UPDATE fraud_activities SET verdict = COALESCE((
+		SELECT to_status FROM fraud_activity_status_changes
+		WHERE fraud_activity_status_changes.fraud_activity_id = fraud_activities.id AND to_status IN ?
+		ORDER BY fraud_activity_status_changes.id DESC LIMIT 1
+	), '') WHERE verdict = '' AND status = ?`, verdicts, models.CaseStatusResolved).Error
+	if err != nil {
+		return fmt.Errorf("❌ Error backfilling verdicts of resolved cases: %v", err)
+	}
+	return nil
+}
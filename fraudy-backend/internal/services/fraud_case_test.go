@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionCase(t *testing.T) {
+	allowed := [][2]string{
+		{models.CaseStatusNew, models.CaseStatusAcknowledged},
+		{models.CaseStatusAcknowledged, models.CaseStatusInvestigating},
+		{models.CaseStatusInvestigating, models.CaseStatusConfirmedFraud},
+		{models.CaseStatusInvestigating, models.CaseStatusFalsePositive},
+		{models.CaseStatusConfirmedFraud, models.CaseStatusResolved},
+		{models.CaseStatusResolved, models.CaseStatusInvestigating},
+	}
+	for _, transition := range allowed {
+		assert.True(t, CanTransitionCase(transition[0], transition[1]), "%s -> %s should be allowed", transition[0], transition[1])
+	}
+
+	rejected := [][2]string{
+		{models.CaseStatusNew, models.CaseStatusResolved},
+		{models.CaseStatusNew, models.CaseStatusNew},
+		{models.CaseStatusResolved, models.CaseStatusConfirmedFraud},
+		{models.CaseStatusConfirmedFraud, models.CaseStatusNew},
+		{models.CaseStatusInvestigating, "closed"},
+	}
+	for _, transition := range rejected {
+		assert.False(t, CanTransitionCase(transition[0], transition[1]), "%s -> %s should be rejected", transition[0], transition[1])
+	}
+}
+
+func TestTransitionFraudActivityRejectsInvalidMoves(t *testing.T) {
+	activity := &models.FraudActivity{Status: models.CaseStatusNew}
+
+	err := TransitionFraudActivity(activity, 1, models.CaseStatusResolved, "done")
+	assert.Equal(t, ErrInvalidTransition{From: models.CaseStatusNew, To: models.CaseStatusResolved}, err)
+	assert.Equal(t, models.CaseStatusNew, activity.Status)
+}
+
+func TestCaseUpdatesKeepVerdictOnResolve(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{"status": models.CaseStatusConfirmedFraud, "verdict": models.CaseStatusConfirmedFraud},
+		caseUpdates(models.CaseStatusConfirmedFraud, ""))
+	assert.Equal(t, map[string]interface{}{"status": models.CaseStatusFalsePositive, "verdict": models.CaseStatusFalsePositive},
+		caseUpdates(models.CaseStatusFalsePositive, ""))
+
+	resolved := caseUpdates(models.CaseStatusResolved, "Funds returned")
+	assert.Equal(t, "Funds returned", resolved["resolution_notes"])
+	assert.NotContains(t, resolved, "verdict")
+
+	reopened := caseUpdates(models.CaseStatusInvestigating, "New payments seen")
+	assert.Equal(t, "", reopened["verdict"])
+	assert.Equal(t, "", reopened["resolution_notes"])
+}
@@ -1,102 +1,183 @@
-package services
-
-import (
-	"fmt"
-	"net/smtp"
-	"encoding/json"
-	"strings"
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-)
-
-func SendEmailNotification(userID int, alert models.Alert) error {
-	var config models.NotificationConfig
-	result := database.DB.Where("user_id = ? AND notification_type = ?", userID, "email").First(&config)
-	if result.Error != nil {
-		return fmt.Errorf("❌ Error fetching email config for user ID %d: %v", userID, result.Error)
-	}
-
-	var toEmails []string
-	if err := json.Unmarshal([]byte(config.RecipientEmails), &toEmails); err != nil {
-		return fmt.Errorf("❌ Error parsing recipient emails: %v", err)
-	}
-
-	fmt.Println("📩 Parsed Recipient Emails:", toEmails)
-
-	addr := fmt.Sprintf("%s:%s", config.SMTPServer, config.SMTPPort)
-	auth := smtp.PlainAuth("", config.EmailSender, config.EmailPassword, config.SMTPServer)
-
-	// 📌 HTML Email Template
-	subject := "🚨 Fraud Alert Triggered!"
-	body := fmt.Sprintf(`
-		<!DOCTYPE html>
-		<html>
-		<head>
-			<style>
-				.container {
-					font-family: Arial, sans-serif;
-					background-color: #f4f4f4;
-					padding: 20px;
-					border-radius: 8px;
-					max-width: 600px;
-					margin: auto;
-				}
-				.header {
-					background-color: #d9534f;
-					color: white;
-					padding: 10px;
-					text-align: center;
-					font-size: 20px;
-					font-weight: bold;
-					border-radius: 5px 5px 0 0;
-				}
-				.content {
-					padding: 15px;
-					background-color: white;
-					border-radius: 0 0 5px 5px;
-				}
-				.footer {
-					margin-top: 10px;
-					font-size: 12px;
-					color: gray;
-					text-align: center;
-				}
-			</style>
-		</head>
-		<body>
-			<div class="container">
-				<div class="header">🚨 Fraud Alert Triggered!</div>
-				<div class="content">
-					<p><strong>Alert Name:</strong> %s</p>
-					<p><strong>Rule Type:</strong> %s</p>
-					<p><strong>Wallet ID:</strong> %s</p>
-					<p><strong>Triggered At:</strong> %s</p>
-					<p>Please review this alert in your <a href="http://fraudy-app.com/dashboard">Fraudy Dashboard</a>.</p>
-				</div>
-				<div class="footer">© 2025 Fraudy Team</div>
-			</div>
-		</body>
-		</html>
-	`, alert.AlertName, alert.RuleType, alert.WalletID, alert.CreatedAt)
-
-	// 📩 Email Headers
-	message := fmt.Sprintf("MIME-Version: 1.0\r\n"+
-		"Content-Type: text/html; charset=\"UTF-8\"\r\n"+
-		"From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n\r\n"+
-		"%s",
-		config.EmailSender,
-		strings.Join(toEmails, ", "),
-		subject,
-		body,
-	)
-
-	err := smtp.SendMail(addr, auth, config.EmailSender, toEmails, []byte(message))
-	if err != nil {
-		return fmt.Errorf("❌ SMTP error: %s", err)
-	}
-
-	fmt.Println("✅ Email notification sent successfully!")
-	return nil
-}
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"os"
+	"slices"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+func getChannelConfig(organizationID uint, channel string) (models.NotificationConfig, error) {
+	var config models.NotificationConfig
+	result := database.DB.Where("organization_id = ? AND notification_type = ?", organizationID, channel).First(&config)
+	if result.Error != nil {
+		return config, fmt.Errorf("❌ Error fetching %s config for organization ID %d: %v", channel, organizationID, result.Error)
+	}
+	return config, DecryptNotificationSecrets(&config)
+}
+
+// SendNotification delivers rendered content through the organization's config
+// for channel.
+func SendNotification(organizationID uint, channel string, rendered RenderedNotification) error {
+	config, err := getChannelConfig(organizationID, channel)
+	if err != nil {
+		return err
+	}
+	return DeliverNotification(config, rendered)
+}
+
+// systemMailConfig is the operator's SMTP account for account emails such as
+// address verification and password reset. It is set with SYSTEM_SMTP_SERVER,
+// SYSTEM_SMTP_PORT, SYSTEM_EMAIL_SENDER and SYSTEM_EMAIL_PASSWORD.
+func systemMailConfig(to string) (models.NotificationConfig, error) {
+	config := models.NotificationConfig{
+		ConfigName:       "system",
+		NotificationType: "email",
+		SMTPServer:       os.Getenv("SYSTEM_SMTP_SERVER"),
+		SMTPPort:         os.Getenv("SYSTEM_SMTP_PORT"),
+		EmailSender:      os.Getenv("SYSTEM_EMAIL_SENDER"),
+		EmailPassword:    os.Getenv("SYSTEM_EMAIL_PASSWORD"),
+	}
+	if config.SMTPServer == "" || config.EmailSender == "" {
+		return config, fmt.Errorf("❌ System mail is not configured: set SYSTEM_SMTP_SERVER and SYSTEM_EMAIL_SENDER")
+	}
+	if config.SMTPPort == "" {
+		config.SMTPPort = "587"
+	}
+	recipients, err := json.Marshal([]string{to})
+	if err != nil {
+		return config, err
+	}
+	config.RecipientEmails = string(recipients)
+	return config, nil
+}
+
+// SendSystemEmail delivers rendered content to a single address through the
+// system mail config.
+func SendSystemEmail(to string, rendered RenderedNotification) error {
+	config, err := systemMailConfig(to)
+	if err != nil {
+		return err
+	}
+	return DeliverNotification(config, rendered)
+}
+
+func DeliverNotification(config models.NotificationConfig, rendered RenderedNotification) error {
+	switch config.NotificationType {
+	case "email":
+		return sendEmail(config, rendered.Subject, rendered.Body)
+	case "slack":
+		return postWebhook("slack", config.SlackWebhook, map[string]string{"text": rendered.Body})
+	case "discord":
+		return postWebhook("discord", config.DiscordChannel, map[string]string{"content": rendered.Body})
+	default:
+		return fmt.Errorf("❌ Unsupported notification channel: %s", config.NotificationType)
+	}
+}
+
+// webhookHosts are the hosts each webhook channel may post to. Webhook URLs
+// are entered by users and fetched by the server, so any other host could
+// point it at internal services.
+var webhookHosts = map[string][]string{
+	"slack":   {"hooks.slack.com"},
+	"discord": {"discord.com", "discordapp.com"},
+}
+
+// ValidateWebhookURL checks that webhookURL is an https URL on one of the
+// hosts of channel. Its errors read after the name of the field.
+func ValidateWebhookURL(channel string, webhookURL string) error {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil || (parsed.Port() != "" && parsed.Port() != "443") {
+		return fmt.Errorf("must be an https URL")
+	}
+	if !slices.Contains(webhookHosts[channel], strings.ToLower(parsed.Hostname())) {
+		return fmt.Errorf("must be on %s", strings.Join(webhookHosts[channel], " or "))
+	}
+	return nil
+}
+
+// webhookClient posts webhooks without following redirects, which could lead
+// away from the allowed hosts.
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+func postWebhook(channel string, webhookURL string, payload map[string]string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("❌ Webhook URL is not configured")
+	}
+	if err := ValidateWebhookURL(channel, webhookURL); err != nil {
+		return fmt.Errorf("❌ Webhook URL %v", err)
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("❌ Error encoding webhook payload: %v", err)
+	}
+
+	resp, err := webhookClient.Post(webhookURL, "application/json", bytes.NewReader(payloadJSON))
+	if err != nil {
+		// The webhook URL is a secret, so report the cause without it.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("❌ Webhook error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("❌ Webhook returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	fmt.Println("✅ Webhook notification sent successfully!")
+	return nil
+}
+
+func sendEmail(config models.NotificationConfig, subject string, body string) error {
+	var toEmails []string
+	if err := json.Unmarshal([]byte(config.RecipientEmails), &toEmails); err != nil {
+		return fmt.Errorf("❌ Error parsing recipient emails: %v", err)
+	}
+
+	fmt.Println("📩 Parsed Recipient Emails:", toEmails)
+
+	addr := fmt.Sprintf("%s:%s", config.SMTPServer, config.SMTPPort)
+	auth := smtp.PlainAuth("", config.EmailSender, config.EmailPassword, config.SMTPServer)
+
+	// 📩 Email Headers
+	message := fmt.Sprintf("MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=\"UTF-8\"\r\n"+
+		"From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n\r\n"+
+		"%s",
+		config.EmailSender,
+		strings.Join(toEmails, ", "),
+		mime.QEncoding.Encode("UTF-8", subject),
+		body,
+	)
+
+	err := smtp.SendMail(addr, auth, config.EmailSender, toEmails, []byte(message))
+	if err != nil {
+		return fmt.Errorf("❌ SMTP error: %s", err)
+	}
+
+	fmt.Println("✅ Email notification sent successfully!")
+	return nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLastOwner is returned when a change would leave an organization without
+// an owner.
+var ErrLastOwner = errors.New("an organization must keep at least one owner")
+
+// CreateOrganization creates an organization within tx and makes ownerID its
+// owner.
+func CreateOrganization(tx *gorm.DB, name string, ownerID int) (models.Organization, error) {
+	organization := models.Organization{Name: name}
+	if err := tx.Create(&organization).Error; err != nil {
+		return organization, err
+	}
+	membership := models.Membership{OrganizationID: organization.ID, UserID: ownerID, Role: models.RoleOwner}
+	if err := tx.Create(&membership).Error; err != nil {
+		return organization, err
+	}
+	return organization, nil
+}
+
+// PersonalOrganizationName names the organization a user gets on registration.
+func PersonalOrganizationName(user models.User) string {
+	return user.Username + "'s organization"
+}
+
+// FindMembership returns the membership of userID in organizationID or, when
+// organizationID is 0, the user's oldest membership.
+func FindMembership(userID int, organizationID uint) (models.Membership, error) {
+	var membership models.Membership
+	query := database.DB.Where("user_id = ?", userID)
+	if organizationID != 0 {
+		query = query.Where("organization_id = ?", organizationID)
+	}
+	err := query.Order("id ASC").First(&membership).Error
+	return membership, err
+}
+
+// OrganizationMemberIDs returns the users who belong to organizationID.
+func OrganizationMemberIDs(organizationID uint) []int {
+	var userIDs []int
+	database.DB.Model(&models.Membership{}).Where("organization_id = ?", organizationID).Pluck("user_id", &userIDs)
+	return userIDs
+}
+
+// ChangeMemberRole sets the role of a membership, refusing to demote the last
+// owner.
+func ChangeMemberRole(membership *models.Membership, role string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if membership.Role == models.RoleOwner && role != models.RoleOwner {
+			if err := ensureAnotherOwner(tx, membership); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(membership).Update("role", role).Error; err != nil {
+			return err
+		}
+		membership.Role = role
+		return nil
+	})
+}
+
+// RemoveMember deletes a membership, refusing to remove the last owner.
+func RemoveMember(membership models.Membership) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if membership.Role == models.RoleOwner {
+			if err := ensureAnotherOwner(tx, &membership); err != nil {
+				return err
+			}
+		}
+		// Hard delete so the user can be added back later.
+		return tx.Unscoped().Delete(&membership).Error
+	})
+}
+
+// ensureAnotherOwner locks the organization's owners and fails unless one
+// besides membership remains.
+func ensureAnotherOwner(tx *gorm.DB, membership *models.Membership) error {
+	var owners []models.Membership
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", membership.OrganizationID, models.RoleOwner).
+		Find(&owners).Error
+	if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		if owner.ID != membership.ID {
+			return nil
+		}
+	}
+	return ErrLastOwner
+}
+
+// organizationScopedTables are the tables whose rows belonged to a single user
+// before organizations existed.
+var organizationScopedTables = []string{
+	"alerts",
+	"fraud_activities",
+	"notification_configs",
+	"notification_templates",
+	"notification_deliveries",
+	"address_lists",
+	"scheduled_reports",
+}
+
+// startupBackfillLock is the Postgres advisory lock key held while the
+// startup backfills run.
+const startupBackfillLock = 4201
+
+// RunStartupBackfills brings rows written by older versions up to date. It
+// holds an advisory lock throughout, so instances starting together wait for
+// each other and the later ones find nothing left to do. A failed backfill is
+// rolled back and logged without stopping the ones after it.
+func RunStartupBackfills() error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", startupBackfillLock).Error; err != nil {
+			return fmt.Errorf("❌ Error locking startup backfills: %v", err)
+		}
+		for _, backfill := range []func(*gorm.DB) error{BackfillOrganizations, BackfillFraudActivityOwners, BackfillCaseVerdicts} {
+			if err := tx.Transaction(backfill); err != nil {
+				fmt.Println(err)
+			}
+		}
+		return nil
+	})
+}
+
+// BackfillOrganizations gives every user without an organization a personal
+// one and moves the rows they created before organizations existed into it.
+// It runs within tx, see RunStartupBackfills.
+func BackfillOrganizations(tx *gorm.DB) error {
+	var users []models.User
+	err := tx.Where("id NOT IN (?)", tx.Model(&models.Membership{}).Select("user_id")).Find(&users).Error
+	if err != nil {
+		return fmt.Errorf("❌ Error fetching users without an organization: %v", err)
+	}
+
+	for _, user := range users {
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			_, err := CreateOrganization(tx, PersonalOrganizationName(user), int(user.ID))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("❌ Error creating organization for user %d: %v", user.ID, err)
+		}
+	}
+
+	for _, table := range organizationScopedTables {
+		err := tx.Exec(fmt.Sprintf(`UPDATE %[1]s SET organization_id = (
+			SELECT organization_id FROM memberships
+			WHERE memberships.user_id = %[1]s.user_id AND memberships.deleted_at IS NULL
+			ORDER BY memberships.id ASC LIMIT 1
+		) WHERE organization_id = 0 AND user_id IN (SELECT user_id FROM memberships)`, table)).Error
+		if err != nil {
+			return fmt.Errorf("❌ Error assigning %s to organizations: %v", table, err)
+		}
+	}
+
+	fmt.Printf("✅ Created personal organizations for %d users\n", len(users))
+	return nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+// AddressReputation aggregates the community reports filed against an address.
+type AddressReputation struct {
+	Address          string  `json:"address"`
+	Reports          int     `json:"reports"`
+	ConfirmedReports int     `json:"confirmed_reports"`
+	PendingReports   int     `json:"pending_reports"`
+	PendingReporters int     `json:"pending_reporters"` // distinct users behind the pending reports
+	RejectedReports  int     `json:"rejected_reports"`
+	NetVotes         int     `json:"net_votes"`
+	Score            float64 `json:"score"` // 0 (no signal) to 1 (confirmed malicious)
+}
+
+// NormalizeReportedAddress lowercases domains and strips any scheme or path
+// so that "https://Fake-DeFi.io/login" and "fake-defi.io" match. Stellar
+// account IDs are returned unchanged.
+func NormalizeReportedAddress(reportType string, address string) string {
+	address = strings.TrimSpace(address)
+	if reportType != models.ReportTypePhishing {
+		return address
+	}
+	address = strings.ToLower(address)
+	if i := strings.Index(address, "://"); i >= 0 {
+		address = address[i+3:]
+	}
+	if i := strings.IndexAny(address, "/?#"); i >= 0 {
+		address = address[:i]
+	}
+	return strings.TrimPrefix(address, "www.")
+}
+
+// GetAddressReputation scores an address from its community reports. A report
+// confirmed by a moderator is conclusive; the distinct users behind pending
+// reports and the reports' net votes add up to a partial score, so one user
+// filing again does not raise it. Rejected reports are ignored.
+func GetAddressReputation(address string) (AddressReputation, error) {
+	reputations, err := GetAddressReputations([]string{address})
+	return reputations[address], err
+}
+
+// GetAddressReputations scores several addresses like GetAddressReputation
+// with a single query. Every address is in the result.
+func GetAddressReputations(addresses []string) (map[string]AddressReputation, error) {
+	reputations := make(map[string]AddressReputation, len(addresses))
+	for _, address := range addresses {
+		reputations[address] = AddressReputation{Address: address}
+	}
+	if len(addresses) == 0 {
+		return reputations, nil
+	}
+
+	var reports []models.ReportedAddress
+	if err := database.DB.Where("address IN ?", addresses).Find(&reports).Error; err != nil {
+		return reputations, err
+	}
+	byAddress := make(map[string][]models.ReportedAddress)
+	for _, report := range reports {
+		byAddress[report.Address] = append(byAddress[report.Address], report)
+	}
+
+	for address, reports := range byAddress {
+		reputations[address] = scoreReputation(address, reports)
+	}
+	return reputations, nil
+}
+
+// scoreReputation aggregates the reports filed against address.
+func scoreReputation(address string, reports []models.ReportedAddress) AddressReputation {
+	reputation := AddressReputation{Address: address}
+
+	pendingReporters := make(map[int]bool)
+	for _, report := range reports {
+		reputation.Reports++
+		switch report.Status {
+		case models.ReportStatusConfirmed:
+			reputation.ConfirmedReports++
+		case models.ReportStatusRejected:
+			reputation.RejectedReports++
+			continue
+		default:
+			reputation.PendingReports++
+			pendingReporters[report.ReportedBy] = true
+		}
+		reputation.NetVotes += report.Upvotes - report.Downvotes
+	}
+
+	reputation.PendingReporters = len(pendingReporters)
+
+	if reputation.ConfirmedReports > 0 {
+		reputation.Score = 1
+	} else if reputation.PendingReporters > 0 {
+		score := 0.2*float64(reputation.PendingReporters) + 0.05*float64(reputation.NetVotes)
+		reputation.Score = math.Max(0, math.Min(0.9, score))
+	}
+	return reputation
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+const reportTopAccounts = 5
+
+// ReportCount is one row of a grouped count in a report. Network is only set
+// for top accounts.
+type ReportCount struct {
+	Key     string
+	Network string
+	Count   int64
+}
+
+// ReportSummary is the data a scheduled report is rendered from.
+type ReportSummary struct {
+	Name          string
+	From          time.Time
+	To            time.Time
+	NewActivities int64
+	ByRule        []ReportCount
+	ByFlag        []ReportCount
+	TopAccounts   []ReportCount
+	OpenCases     int64
+}
+
+// openCaseStatuses are the statuses of cases still waiting on an investigator.
+var openCaseStatuses = []string{models.CaseStatusNew, models.CaseStatusAcknowledged, models.CaseStatusInvestigating}
+
+// minReportInterval is the least time allowed between two runs of a report.
+const minReportInterval = time.Hour
+
+// NextReportRun returns the first time after after that schedule fires in
+// timezone. Schedules that fire more than once within minReportInterval,
+// including short @every intervals, are rejected.
+func NextReportRun(schedule string, timezone string, after time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unknown timezone %q", timezone)
+	}
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid schedule: %v", err)
+	}
+	next := parsed.Next(after.In(location))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("invalid schedule: it never fires")
+	}
+	// A week of runs covers every pattern a cron expression can repeat.
+	for run, end := next, next.Add(7*24*time.Hour); run.Before(end); {
+		following := parsed.Next(run)
+		if following.IsZero() {
+			break
+		}
+		if following.Sub(run) < minReportInterval {
+			return time.Time{}, fmt.Errorf("invalid schedule: reports can run at most once an hour")
+		}
+		run = following
+	}
+	return next.UTC(), nil
+}
+
+// ReportChannels returns the lowercased channels a report is sent through,
+// falling back to email like alerts do.
+func ReportChannels(report models.ScheduledReport) []string {
+	var channels []string
+	if err := json.Unmarshal([]byte(report.Channels), &channels); err != nil || len(channels) == 0 {
+		return []string{"email"}
+	}
+	for i := range channels {
+		channels[i] = strings.ToLower(channels[i])
+	}
+	return channels
+}
+
+// BuildReportSummary aggregates the organization's activities created in
+// [from, to).
+func BuildReportSummary(organizationID uint, name string, from time.Time, to time.Time) (ReportSummary, error) {
+	summary := ReportSummary{Name: name, From: from, To: to}
+	activities := func() *gorm.DB {
+		return database.DB.Model(&models.FraudActivity{}).
+			Where("organization_id = ? AND created_at >= ? AND created_at < ?", organizationID, from, to)
+	}
+
+	if err := activities().Count(&summary.NewActivities).Error; err != nil {
+		return summary, err
+	}
+	for _, group := range []struct {
+		column string
+		target *[]ReportCount
+	}{
+		{"type", &summary.ByRule},
+		{"flag", &summary.ByFlag},
+	} {
+		err := activities().Select(group.column + " AS key, COUNT(*) AS count").
+			Group(group.column).Order("count DESC").Scan(group.target).Error
+		if err != nil {
+			return summary, err
+		}
+	}
+	// Every alert on a wallet records its own activity, so accounts are ranked
+	// by distinct transactions, separately on each network.
+	err := activities().Select("account AS key, network, COUNT(DISTINCT transaction_hash) AS count").
+		Group("account, network").Order("count DESC").Limit(reportTopAccounts).Scan(&summary.TopAccounts).Error
+	if err != nil {
+		return summary, err
+	}
+	err = database.DB.Model(&models.FraudActivity{}).
+		Where("organization_id = ? AND status IN ?", organizationID, openCaseStatuses).Count(&summary.OpenCases).Error
+	return summary, err
+}
+
+// SendScheduledReport renders the report for the period since its last run
+// and sends it through each of its channels. It returns the failures joined
+// into one error.
+func SendScheduledReport(report models.ScheduledReport, now time.Time) error {
+	from := report.CreatedAt
+	if report.LastRunAt != nil {
+		from = *report.LastRunAt
+	}
+	summary, err := BuildReportSummary(report.OrganizationID, report.Name, from, now)
+	if err != nil {
+		return err
+	}
+
+	data := NewNotificationData(models.Alert{UserID: report.UserID, OrganizationID: report.OrganizationID})
+	data.Report = &summary
+	data.TriggeredAt = now
+
+	var failures []string
+	for _, channel := range ReportChannels(report) {
+		rendered, err := RenderNotification(report.OrganizationID, channel, EventReport, data)
+		if err == nil {
+			err = SendNotification(report.OrganizationID, channel, rendered)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", channel, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// ProcessScheduledReports sends every enabled report that is due. Each report
+// is claimed by moving its next_run_at forward with a guarded update, so when
+// several replicas run this at once only one of them sends it.
+func ProcessScheduledReports() {
+	now := time.Now().UTC()
+	var due []models.ScheduledReport
+	if err := database.DB.Where("enabled = ? AND next_run_at <= ?", true, now).Find(&due).Error; err != nil {
+		fmt.Println("❌ Error fetching scheduled reports:", err)
+		return
+	}
+
+	for _, report := range due {
+		next, err := NextReportRun(report.Schedule, report.Timezone, now)
+		if err != nil {
+			fmt.Printf("❌ Disabling scheduled report %d: %v\n", report.ID, err)
+			database.DB.Model(&report).Updates(map[string]interface{}{"enabled": false, "last_error": err.Error()})
+			continue
+		}
+
+		claim := database.DB.Model(&models.ScheduledReport{}).
+			Where("id = ? AND next_run_at = ?", report.ID, report.NextRunAt).
+			Update("next_run_at", next)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue // another replica claimed it
+		}
+
+		lastError := ""
+		if err := SendScheduledReport(report, now); err != nil {
+			fmt.Printf("❌ Scheduled report %d failed: %v\n", report.ID, err)
+			lastError = err.Error()
+		} else {
+			fmt.Printf("✅ Sent scheduled report %d (Organization: %d)\n", report.ID, report.OrganizationID)
+		}
+		database.DB.Model(&models.ScheduledReport{}).Where("id = ?", report.ID).
+			Updates(map[string]interface{}{"last_run_at": now, "last_error": lastError})
+	}
+}
+
+func RunScheduledReportWorker(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ProcessScheduledReports()
+		}
+	}
+}
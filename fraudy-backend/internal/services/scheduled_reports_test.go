@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextReportRun(t *testing.T) {
+	after := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC) // a Wednesday
+
+	next, err := NextReportRun("0 9 * * 1", "UTC", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), next)
+
+	next, err = NextReportRun("@daily", "Europe/Istanbul", after)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 5, 21, 0, 0, 0, time.UTC), next)
+
+	_, err = NextReportRun("every monday", "UTC", after)
+	assert.Error(t, err)
+	_, err = NextReportRun("@daily", "Mars/Olympus", after)
+	assert.Error(t, err)
+}
+
+func TestNextReportRunRejectsFrequentSchedules(t *testing.T) {
+	after := time.Date(2025, 3, 5, 10, 30, 0, 0, time.UTC)
+
+	for _, schedule := range []string{"@hourly", "@every 2h", "0 9,10 * * *", "0 9 * * 1-5", "0 9 1 * *"} {
+		_, err := NextReportRun(schedule, "Europe/Istanbul", after)
+		assert.NoError(t, err, schedule)
+	}
+	for _, schedule := range []string{"* * * * *", "*/5 * * * *", "@every 1m", "@every 59m", "0,30 9 * * *"} {
+		_, err := NextReportRun(schedule, "UTC", after)
+		assert.EqualError(t, err, "invalid schedule: reports can run at most once an hour", schedule)
+	}
+	_, err := NextReportRun("0 9 30 2 *", "UTC", after)
+	assert.EqualError(t, err, "invalid schedule: it never fires")
+}
+
+func TestRenderDefaultReportTemplates(t *testing.T) {
+	data := NewNotificationData(models.Alert{})
+	data.Report = &ReportSummary{
+		Name:          "Weekly <Summary>",
+		From:          time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC),
+		To:            time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+		NewActivities: 4,
+		ByRule:        []ReportCount{{Key: "highFailureRate", Count: 4}},
+		TopAccounts:   []ReportCount{{Key: "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ", Count: 4}},
+		OpenCases:     1,
+	}
+
+	for _, channel := range []string{"email", "slack", "discord"} {
+		source, err := defaultTemplates.ReadFile("templates/" + channel + "_report.tmpl")
+		require.NoError(t, err)
+		rendered, err := RenderTemplateSource(channel, string(source), data)
+		require.NoError(t, err, channel)
+		assert.Contains(t, rendered.Body, "highFailureRate", channel)
+		assert.Contains(t, rendered.Body, "GBPUZMFJIUJ5ZVJR4YIJ4QA2CIRQGAZUWOOP4UJ5K7W7W5EEQRMEFLJZ", channel)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"os"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/pkg/secrets"
+)
+
+var secretKeyring *secrets.Keyring
+
+// InitSecrets loads the master keys used to encrypt notification secrets.
+// NOTIFICATION_MASTER_KEYS is a comma separated list of "id:base64key" pairs
+// and NOTIFICATION_ACTIVE_KEY_ID selects the key used for new secrets.
+func InitSecrets() error {
+	keyring, err := secrets.NewKeyring(os.Getenv("NOTIFICATION_MASTER_KEYS"), os.Getenv("NOTIFICATION_ACTIVE_KEY_ID"))
+	if err != nil {
+		return fmt.Errorf("❌ Error loading notification master keys: %v", err)
+	}
+	secretKeyring = keyring
+	return nil
+}
+
+// EncryptNotificationSecrets encrypts the secret fields of config in place.
+func EncryptNotificationSecrets(config *models.NotificationConfig) error {
+	var err error
+	if config.EmailPassword, err = secretKeyring.Encrypt(config.EmailPassword); err != nil {
+		return fmt.Errorf("❌ Error encrypting email password: %v", err)
+	}
+	if config.SlackWebhook, err = secretKeyring.Encrypt(config.SlackWebhook); err != nil {
+		return fmt.Errorf("❌ Error encrypting Slack webhook: %v", err)
+	}
+	if config.DiscordChannel, err = secretKeyring.Encrypt(config.DiscordChannel); err != nil {
+		return fmt.Errorf("❌ Error encrypting Discord webhook: %v", err)
+	}
+	return nil
+}
+
+// DecryptNotificationSecrets decrypts the secret fields of config in place.
+func DecryptNotificationSecrets(config *models.NotificationConfig) error {
+	var err error
+	if config.EmailPassword, err = secretKeyring.Decrypt(config.EmailPassword); err != nil {
+		return fmt.Errorf("❌ Error decrypting email password: %v", err)
+	}
+	if config.SlackWebhook, err = secretKeyring.Decrypt(config.SlackWebhook); err != nil {
+		return fmt.Errorf("❌ Error decrypting Slack webhook: %v", err)
+	}
+	if config.DiscordChannel, err = secretKeyring.Decrypt(config.DiscordChannel); err != nil {
+		return fmt.Errorf("❌ Error decrypting Discord webhook: %v", err)
+	}
+	return nil
+}
+
+// RotateNotificationSecrets re-wraps every stored secret with the active master
+// key and encrypts secrets that are still stored in plaintext. It returns the
+// number of configs that were updated.
+func RotateNotificationSecrets() (int, error) {
+	var configs []models.NotificationConfig
+	if err := database.DB.Unscoped().Find(&configs).Error; err != nil {
+		return 0, fmt.Errorf("❌ Error fetching notification configs: %v", err)
+	}
+
+	updated := 0
+	for _, config := range configs {
+		emailPassword, passwordChanged, err := secretKeyring.Rewrap(config.EmailPassword)
+		if err != nil {
+			return updated, fmt.Errorf("❌ Error rotating email password of config %d: %v", config.ID, err)
+		}
+		slackWebhook, webhookChanged, err := secretKeyring.Rewrap(config.SlackWebhook)
+		if err != nil {
+			return updated, fmt.Errorf("❌ Error rotating Slack webhook of config %d: %v", config.ID, err)
+		}
+		discordChannel, discordChanged, err := secretKeyring.Rewrap(config.DiscordChannel)
+		if err != nil {
+			return updated, fmt.Errorf("❌ Error rotating Discord webhook of config %d: %v", config.ID, err)
+		}
+		if !passwordChanged && !webhookChanged && !discordChanged {
+			continue
+		}
+
+		result := database.DB.Unscoped().Model(&config).Updates(map[string]interface{}{
+			"email_password":  emailPassword,
+			"slack_webhook":   slackWebhook,
+			"discord_channel": discordChannel,
+		})
+		if result.Error != nil {
+			return updated, fmt.Errorf("❌ Error saving config %d: %v", config.ID, result.Error)
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// RotateTOTPSecrets re-wraps the TOTP secrets of users with the active master
+// key. It returns the number of users that were updated.
+func RotateTOTPSecrets() (int, error) {
+	var users []models.User
+	if err := database.DB.Unscoped().Where("totp_secret <> ''").Find(&users).Error; err != nil {
+		return 0, fmt.Errorf("❌ Error fetching users with TOTP secrets: %v", err)
+	}
+
+	updated := 0
+	for _, user := range users {
+		secret, changed, err := secretKeyring.Rewrap(user.TOTPSecret)
+		if err != nil {
+			return updated, fmt.Errorf("❌ Error rotating TOTP secret of user %d: %v", user.ID, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := database.DB.Unscoped().Model(&user).Update("totp_secret", secret).Error; err != nil {
+			return updated, fmt.Errorf("❌ Error saving TOTP secret of user %d: %v", user.ID, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
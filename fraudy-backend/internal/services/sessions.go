@@ -0,0 +1,211 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; the session has been revoked")
+)
+
+// AccessTokenTTL is how long an access token is valid, from ACCESS_TOKEN_TTL
+// (default 15m).
+func AccessTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 15 * time.Minute
+}
+
+// refreshTokenTTL is how long a session stays signed in without being
+// refreshed, from REFRESH_TOKEN_TTL (default 30 days).
+func refreshTokenTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 30 * 24 * time.Hour
+}
+
+// hashSecretToken is how refresh and account tokens are stored, so a leaked
+// database cannot be used to sign in.
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSecretToken returns 256 random bits, URL-safe encoded.
+func newSecretToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// CreateSession signs userID in on a new device and returns the session with
+// its refresh token.
+func CreateSession(userID int, userAgent string, ipAddress string) (models.Session, string, error) {
+	token, err := newSecretToken()
+	if err != nil {
+		return models.Session{}, "", err
+	}
+	now := time.Now()
+	session := models.Session{
+		UserID:           userID,
+		RefreshTokenHash: hashSecretToken(token),
+		UserAgent:        userAgent,
+		IPAddress:        ipAddress,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(refreshTokenTTL()),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return session, "", err
+	}
+	return session, token, nil
+}
+
+// RotateSession exchanges a refresh token for a new one and extends its
+// session. Presenting a refresh token that was already rotated out means it
+// leaked, so the whole session is revoked.
+func RotateSession(refreshToken string) (models.Session, string, error) {
+	hash := hashSecretToken(refreshToken)
+	now := time.Now()
+	var session models.Session
+	var current, previous *models.Session
+	if database.DB.Where("refresh_token_hash = ?", hash).First(&session).Error == nil {
+		current = &session
+	} else if database.DB.Where("previous_token_hash = ? AND revoked_at IS NULL", hash).First(&session).Error == nil {
+		previous = &session
+	}
+	if err := checkRefresh(current, previous, now); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			RevokeSession(session.UserID, session.ID)
+		}
+		return session, "", err
+	}
+
+	next, err := newSecretToken()
+	if err != nil {
+		return session, "", err
+	}
+	// Guard on the current hash so two refreshes with the same token cannot
+	// both succeed.
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  hashSecretToken(next),
+			"previous_token_hash": hash,
+			"last_used_at":        now,
+			"expires_at":          now.Add(refreshTokenTTL()),
+		})
+	if result.Error != nil {
+		return session, "", result.Error
+	}
+	if err := checkRotated(result.RowsAffected); err != nil {
+		return session, "", err
+	}
+	return session, next, nil
+}
+
+// checkRefresh decides whether a refresh token may be rotated. current is the
+// session the token is the live token of and previous the active session it
+// was rotated out of, each nil when there is none. A token found only as a
+// previous one is being reused.
+func checkRefresh(current *models.Session, previous *models.Session, now time.Time) error {
+	switch {
+	case current != nil:
+		if !sessionUsable(*current, now) {
+			return ErrInvalidRefreshToken
+		}
+		return nil
+	case previous != nil:
+		return ErrRefreshTokenReused
+	default:
+		return ErrInvalidRefreshToken
+	}
+}
+
+// checkRotated turns the rows the guarded rotation updated into its outcome.
+// None means a concurrent refresh with the same token rotated it first.
+func checkRotated(rowsAffected int64) error {
+	if rowsAffected == 0 {
+		return ErrInvalidRefreshToken
+	}
+	return nil
+}
+
+// sessionUsable reports whether session is neither revoked nor expired at now.
+func sessionUsable(session models.Session, now time.Time) bool {
+	return session.RevokedAt == nil && !now.After(session.ExpiresAt)
+}
+
+// SessionActive reports whether sessionID belongs to userID and is neither
+// revoked nor expired.
+func SessionActive(sessionID uint, userID int) bool {
+	var count int64
+	database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+// ActiveSessions lists the sessions userID is still signed in with, most
+// recently used first.
+func ActiveSessions(userID int) ([]models.Session, error) {
+	var sessions []models.Session
+	err := database.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// RevokeSession signs one of userID's sessions out. It reports whether an
+// active session was revoked.
+func RevokeSession(userID int, sessionID uint) (bool, error) {
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// RevokeUserSessions signs userID out everywhere and returns how many
+// sessions were revoked.
+func RevokeUserSessions(userID int) (int64, error) {
+	result := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+const streamTicketTTL = 30 * time.Second
+
+// IssueStreamTicket returns a ticket that opens one event stream for userID
+// on sessionID. EventSource cannot send an Authorization header, so the ticket
+// goes in the URL in place of the access token; it works once and only for
+// seconds, so a URL that ends up in a log is of no use. Unlike
+// IssueAccountToken it leaves earlier tickets valid, so several tabs can
+// connect at once.
+func IssueStreamTicket(userID int, sessionID uint) (string, time.Time, error) {
+	ticket, err := newSecretToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(streamTicketTTL)
+	err = database.DB.Create(&models.AccountToken{
+		UserID:    userID,
+		Purpose:   models.TokenPurposeStreamTicket,
+		TokenHash: hashSecretToken(ticket),
+		ExpiresAt: expiresAt,
+		SessionID: &sessionID,
+	}).Error
+	return ticket, expiresAt, err
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretTokensAreRandomAndHashed(t *testing.T) {
+	first, err := newSecretToken()
+	require.NoError(t, err)
+	second, err := newSecretToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.Len(t, first, 43)
+	assert.Len(t, hashSecretToken(first), 64)
+	assert.Equal(t, hashSecretToken(first), hashSecretToken(first))
+	assert.NotEqual(t, hashSecretToken(first), hashSecretToken(second))
+}
+
+func TestTokenTTLs(t *testing.T) {
+	t.Setenv("ACCESS_TOKEN_TTL", "")
+	t.Setenv("REFRESH_TOKEN_TTL", "")
+	assert.Equal(t, 15*time.Minute, AccessTokenTTL())
+	assert.Equal(t, 30*24*time.Hour, refreshTokenTTL())
+
+	t.Setenv("ACCESS_TOKEN_TTL", "5m")
+	t.Setenv("REFRESH_TOKEN_TTL", "-1h")
+	assert.Equal(t, 5*time.Minute, AccessTokenTTL())
+	assert.Equal(t, 30*24*time.Hour, refreshTokenTTL())
+}
+
+func TestCheckRefresh(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+	active := models.Session{ExpiresAt: now.Add(time.Hour)}
+	expired := models.Session{ExpiresAt: now.Add(-time.Hour)}
+	revoked := models.Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}
+
+	assert.NoError(t, checkRefresh(&active, nil, now))
+	assert.ErrorIs(t, checkRefresh(&expired, nil, now), ErrInvalidRefreshToken)
+	assert.ErrorIs(t, checkRefresh(&revoked, nil, now), ErrInvalidRefreshToken)
+	assert.ErrorIs(t, checkRefresh(nil, &active, now), ErrRefreshTokenReused, "token already rotated out")
+	assert.ErrorIs(t, checkRefresh(nil, nil, now), ErrInvalidRefreshToken, "unknown token")
+}
+
+func TestCheckRotatedRejectsTheLosingConcurrentRefresh(t *testing.T) {
+	assert.NoError(t, checkRotated(1))
+	assert.ErrorIs(t, checkRotated(0), ErrInvalidRefreshToken)
+}
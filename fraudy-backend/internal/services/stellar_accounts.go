@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var ErrStellarAccountLinked = errors.New("this Stellar account is linked to another user")
+
+// LinkStellarAccount lets userID sign in with account. Linking an account
+// the user already has is a no-op.
+func LinkStellarAccount(userID int, account string) (models.StellarAccount, error) {
+	var linked models.StellarAccount
+	err := database.DB.Where("account = ?", account).First(&linked).Error
+	if err == nil {
+		if linked.UserID != userID {
+			return linked, ErrStellarAccountLinked
+		}
+		return linked, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return linked, err
+	}
+
+	linked = models.StellarAccount{UserID: userID, Account: account}
+	// The unique index on account keeps a concurrent link from succeeding too.
+	err = database.DB.Create(&linked).Error
+	return linked, err
+}
+
+// UnlinkStellarAccount stops userID signing in with account and reports
+// whether it was linked.
+func UnlinkStellarAccount(userID int, account string) (bool, error) {
+	result := database.DB.Unscoped().Where("user_id = ? AND account = ?", userID, account).
+		Delete(&models.StellarAccount{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UserStellarAccounts lists the accounts userID may sign in with.
+func UserStellarAccounts(userID int) ([]models.StellarAccount, error) {
+	var accounts []models.StellarAccount
+	err := database.DB.Where("user_id = ?", userID).Order("created_at").Find(&accounts).Error
+	return accounts, err
+}
+
+// FindUserByStellarAccount returns the user account is linked to.
+func FindUserByStellarAccount(account string) (models.User, error) {
+	var user models.User
+	var linked models.StellarAccount
+	if err := database.DB.Where("account = ?", account).First(&linked).Error; err != nil {
+		return user, err
+	}
+	err := database.DB.First(&user, linked.UserID).Error
+	return user, err
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+const (
+	EventAlert         = "alert"
+	EventDigest        = "digest"
+	EventReport        = "report"
+	EventVerifyEmail   = "verify_email"
+	EventPasswordReset = "password_reset"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// NotificationData is the data every notification template is executed with.
+type NotificationData struct {
+	Alert             models.Alert
+	Activity          models.FraudActivity
+	Activities        []models.FraudActivity
+	TransactionHashes []string
+	Report            *ReportSummary // set for scheduled reports only
+	Account           *AccountEmail  // set for account emails only
+	TriggeredAt       time.Time
+	DashboardURL      string
+	Year              int
+}
+
+type RenderedNotification struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+func dashboardURL() string {
+	if url := os.Getenv("DASHBOARD_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:5173/dashboard"
+}
+
+// NewNotificationData fills in the fields shared by every channel and event type.
+func NewNotificationData(alert models.Alert, activities ...models.FraudActivity) NotificationData {
+	data := NotificationData{
+		Alert:        alert,
+		Activities:   activities,
+		TriggeredAt:  time.Now(),
+		DashboardURL: dashboardURL(),
+		Year:         time.Now().Year(),
+	}
+	if len(activities) > 0 {
+		data.Activity = activities[len(activities)-1]
+		if !data.Activity.CreatedAt.IsZero() {
+			data.TriggeredAt = data.Activity.CreatedAt
+		}
+		json.Unmarshal([]byte(data.Activity.TransactionHashes), &data.TransactionHashes)
+	}
+	return data
+}
+
+// loadTemplateSource resolves a template in order of precedence: the
+// organization's own override, the operator's NOTIFICATION_TEMPLATES_DIR, then
+// the built-in default.
+func loadTemplateSource(organizationID uint, channel string, eventType string) (string, error) {
+	var override models.NotificationTemplate
+	result := database.DB.Where("organization_id = ? AND channel = ? AND event_type = ?", organizationID, channel, eventType).First(&override)
+	if result.Error == nil {
+		return override.Source, nil
+	}
+
+	name := fmt.Sprintf("%s_%s.tmpl", channel, eventType)
+	if dir := os.Getenv("NOTIFICATION_TEMPLATES_DIR"); dir != "" {
+		if source, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return string(source), nil
+		}
+	}
+
+	source, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("❌ No template for channel %s and event %s", channel, eventType)
+	}
+	return string(source), nil
+}
+
+// RenderTemplateSource executes the "subject" and "body" templates of source.
+// Email bodies are rendered with html/template so alert fields are escaped.
+func RenderTemplateSource(channel string, source string, data NotificationData) (RenderedNotification, error) {
+	var rendered RenderedNotification
+
+	textTmpl, err := texttemplate.New(channel).Parse(source)
+	if err != nil {
+		return rendered, fmt.Errorf("❌ Error parsing template: %v", err)
+	}
+	var subject bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return rendered, fmt.Errorf("❌ Error rendering subject: %v", err)
+	}
+	rendered.Subject = strings.TrimSpace(subject.String())
+
+	var body bytes.Buffer
+	if channel == "email" {
+		htmlTmpl, err := htmltemplate.New(channel).Parse(source)
+		if err != nil {
+			return rendered, fmt.Errorf("❌ Error parsing template: %v", err)
+		}
+		err = htmlTmpl.ExecuteTemplate(&body, "body", data)
+		if err != nil {
+			return rendered, fmt.Errorf("❌ Error rendering body: %v", err)
+		}
+	} else if err := textTmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return rendered, fmt.Errorf("❌ Error rendering body: %v", err)
+	}
+	rendered.Body = strings.TrimSpace(body.String())
+
+	return rendered, nil
+}
+
+func RenderNotification(organizationID uint, channel string, eventType string, data NotificationData) (RenderedNotification, error) {
+	source, err := loadTemplateSource(organizationID, channel, eventType)
+	if err != nil {
+		return RenderedNotification{}, err
+	}
+	return RenderTemplateSource(channel, source, data)
+}
+
+// DefaultTemplateSource returns the template a user would get without an override.
+func DefaultTemplateSource(channel string, eventType string) (string, error) {
+	return loadTemplateSource(0, channel, eventType)
+}
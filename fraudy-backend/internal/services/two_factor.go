@@ -0,0 +1,338 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/pkg/totp"
+
+	"gorm.io/gorm"
+)
+
+const (
+	loginChallengeTTL         = 5 * time.Minute
+	maxLoginChallengeAttempts = 5
+	maxSecondFactorAttempts   = 5 // wrong codes across all challenges before the user is locked out
+	secondFactorLockout       = 15 * time.Minute
+	recoveryCodeCount         = 10
+	totpSkew                  = 1 // accept the previous and next code for clock drift
+)
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotEnrolled    = errors.New("start two-factor enrollment first")
+	ErrInvalidTOTPCode    = errors.New("invalid two-factor code")
+	ErrTOTPLocked         = errors.New("too many invalid two-factor codes, try again later")
+)
+
+// TOTPEnrollment is what an authenticator app needs to add the account.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// totpIssuer names the service in authenticator apps, from TOTP_ISSUER.
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Fraudy"
+}
+
+// BeginTOTPEnrollment gives user a new secret. Two-factor authentication is
+// not required until ConfirmTOTPEnrollment sees a code from it, so starting
+// over replaces the pending secret.
+func BeginTOTPEnrollment(user models.User) (TOTPEnrollment, error) {
+	if user.TOTPEnabledAt != nil {
+		return TOTPEnrollment{}, ErrTOTPAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	encrypted, err := secretKeyring.Encrypt(secret)
+	if err != nil {
+		return TOTPEnrollment{}, fmt.Errorf("❌ Error encrypting TOTP secret: %v", err)
+	}
+	err = database.DB.Model(&models.User{}).Where("id = ? AND totp_enabled_at IS NULL", user.ID).
+		Updates(map[string]interface{}{"totp_secret": encrypted, "totp_last_step": 0}).Error
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	return TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment turns two-factor authentication on once code shows
+// the authenticator is set up, and returns the user's recovery codes.
+func ConfirmTOTPEnrollment(user models.User, code string) ([]string, error) {
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+	if err := limitSecondFactor(user, func() error { return useTOTPCode(user, code) }); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ? AND totp_enabled_at IS NULL", user.ID).
+			Update("totp_enabled_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPAlreadyEnabled
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, int(user.ID))
+		return err
+	})
+	return codes, err
+}
+
+// DisableTOTP turns two-factor authentication off after checking code, which
+// may be a recovery code.
+func DisableTOTP(user models.User, code string) error {
+	if user.TOTPEnabledAt == nil {
+		return ErrTOTPNotEnabled
+	}
+	if err := VerifySecondFactor(user, code); err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes replaces user's recovery codes after checking a
+// TOTP code, so the old ones stop working.
+func RegenerateRecoveryCodes(user models.User, code string) ([]string, error) {
+	if user.TOTPEnabledAt == nil {
+		return nil, ErrTOTPNotEnabled
+	}
+	if err := limitSecondFactor(user, func() error { return useTOTPCode(user, code) }); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, int(user.ID))
+		return err
+	})
+	return codes, err
+}
+
+// VerifySecondFactor accepts a current TOTP code or an unused recovery code.
+// Too many wrong codes lock the user out for a while, see limitSecondFactor.
+func VerifySecondFactor(user models.User, code string) error {
+	code, isTOTP := secondFactorCode(code)
+	return limitSecondFactor(user, func() error {
+		if isTOTP {
+			return useTOTPCode(user, code)
+		}
+		return useRecoveryCode(int(user.ID), code)
+	})
+}
+
+// secondFactorCode strips the spaces people type or paste into code and
+// reports whether it is a TOTP code rather than a recovery code.
+func secondFactorCode(code string) (string, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	return code, len(code) == totp.Digits
+}
+
+// limitSecondFactor runs check unless user is locked out. Wrong codes are
+// counted on the user rather than on a login challenge, so starting new
+// challenges does not give an attacker more guesses; the
+// maxSecondFactorAttempts-th wrong code locks them out for
+// secondFactorLockout. A right code resets the count.
+func limitSecondFactor(user models.User, check func() error) error {
+	// Read the counters fresh: user may have been loaded before other attempts.
+	var state models.User
+	err := database.DB.Select("id, totp_failed_attempts, totp_locked_until").First(&state, user.ID).Error
+	if err != nil {
+		return err
+	}
+	if secondFactorLocked(state, time.Now()) {
+		return ErrTOTPLocked
+	}
+
+	err = check()
+	switch secondFactorOutcome(state, err) {
+	case countWrongCode:
+		// One statement, so concurrent wrong codes are all counted.
+		locks := gorm.Expr("totp_failed_attempts + 1 >= ?", maxSecondFactorAttempts)
+		database.DB.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"totp_failed_attempts": gorm.Expr("CASE WHEN ? THEN 0 ELSE totp_failed_attempts + 1 END", locks),
+			"totp_locked_until":    gorm.Expr("CASE WHEN ? THEN ? ELSE totp_locked_until END", locks, time.Now().Add(secondFactorLockout)),
+		})
+	case resetWrongCodes:
+		database.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("totp_failed_attempts", 0)
+	}
+	return err
+}
+
+// secondFactorLocked reports whether state is locked out of second-factor
+// checks at now.
+func secondFactorLocked(state models.User, now time.Time) bool {
+	return state.TOTPLockedUntil != nil && now.Before(*state.TOTPLockedUntil)
+}
+
+// secondFactorRecord is what limitSecondFactor records after a check.
+type secondFactorRecord int
+
+const (
+	keepWrongCodes  secondFactorRecord = iota // nothing to record
+	countWrongCode                            // a wrong code, which may lock the user out
+	resetWrongCodes                           // a right code after wrong ones
+)
+
+// secondFactorOutcome decides what limitSecondFactor records for a check of
+// state that returned err. Errors other than a wrong code, such as a failed
+// query, are not the user's guess and are not counted.
+func secondFactorOutcome(state models.User, err error) secondFactorRecord {
+	switch {
+	case errors.Is(err, ErrInvalidTOTPCode):
+		return countWrongCode
+	case err == nil && state.TOTPFailedAttempts > 0:
+		return resetWrongCodes
+	default:
+		return keepWrongCodes
+	}
+}
+
+// IssueLoginChallenge returns the token a user who got their password right
+// exchanges, together with a second factor, for a session.
+func IssueLoginChallenge(userID int) (string, time.Time, error) {
+	return IssueAccountToken(userID, models.TokenPurposeLoginChallenge, loginChallengeTTL)
+}
+
+// CompleteLoginChallenge checks code against the challenge's user and
+// consumes the challenge. A challenge stops working after a few wrong codes
+// so it cannot be used to guess one.
+func CompleteLoginChallenge(token string, code string) (models.User, error) {
+	var user models.User
+	var challenge models.AccountToken
+	err := database.DB.Where("token_hash = ? AND purpose = ?", hashSecretToken(token), models.TokenPurposeLoginChallenge).
+		First(&challenge).Error
+	if err != nil || !loginChallengeUsable(challenge, time.Now()) {
+		return user, ErrInvalidAccountToken
+	}
+	if err := database.DB.First(&user, challenge.UserID).Error; err != nil {
+		return user, ErrInvalidAccountToken
+	}
+
+	if err := VerifySecondFactor(user, code); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			database.DB.Model(&models.AccountToken{}).Where("id = ?", challenge.ID).
+				UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+		}
+		return user, err
+	}
+	if _, err := ConsumeAccountToken(token, models.TokenPurposeLoginChallenge); err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
+// loginChallengeUsable reports whether challenge is unused, unexpired and has
+// wrong codes left at now.
+func loginChallengeUsable(challenge models.AccountToken, now time.Time) bool {
+	return challenge.UsedAt == nil && challenge.Attempts < maxLoginChallengeAttempts && !now.After(challenge.ExpiresAt)
+}
+
+// useTOTPCode checks a TOTP code and records its time step, so the same code
+// is not accepted twice.
+func useTOTPCode(user models.User, code string) error {
+	secret, err := secretKeyring.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return fmt.Errorf("❌ Error decrypting TOTP secret: %v", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok || step <= user.TOTPLastStep {
+		return ErrInvalidTOTPCode
+	}
+	// Guard on the last step so two requests with the same code cannot both win.
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+func useRecoveryCode(userID int, code string) error {
+	if code == "" {
+		return ErrInvalidTOTPCode
+	}
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashSecretToken(strings.ToLower(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTOTPCode
+	}
+	return nil
+}
+
+// replaceRecoveryCodes deletes userID's recovery codes and stores new ones.
+func replaceRecoveryCodes(tx *gorm.DB, userID int) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hashSecretToken(code)})
+	}
+	return codes, tx.Create(&records).Error
+}
+
+// newRecoveryCode returns a code like "7kq2m-x9d4p", long enough that it
+// cannot be mistaken for a TOTP code.
+func newRecoveryCode() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for i, b := range raw {
+		if i == 5 {
+			code.WriteByte('-')
+		}
+		code.WriteByte(alphabet[int(b)%len(alphabet)])
+	}
+	return code.String(), nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fraudy-backend/internal/models"
+	"fraudy-backend/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRecoveryCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 20; i++ {
+		code, err := newRecoveryCode()
+		require.NoError(t, err)
+		assert.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestTOTPIssuer(t *testing.T) {
+	t.Setenv("TOTP_ISSUER", "")
+	assert.Equal(t, "Fraudy", totpIssuer())
+	t.Setenv("TOTP_ISSUER", "Fraudy Staging")
+	assert.Equal(t, "Fraudy Staging", totpIssuer())
+}
+
+func TestSecondFactorCodeRouting(t *testing.T) {
+	code, isTOTP := secondFactorCode(" 123 456 ")
+	assert.Equal(t, "123456", code)
+	assert.True(t, isTOTP)
+
+	code, isTOTP = secondFactorCode("abcde-fghij")
+	assert.Equal(t, "abcde-fghij", code)
+	assert.False(t, isTOTP)
+
+	_, isTOTP = secondFactorCode("12345")
+	assert.False(t, isTOTP, "too short for a TOTP code")
+}
+
+func TestSecondFactorLockoutAndReset(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+	earlier := now.Add(-time.Minute)
+	assert.False(t, secondFactorLocked(models.User{}, now))
+	assert.True(t, secondFactorLocked(models.User{TOTPLockedUntil: &later}, now))
+	assert.False(t, secondFactorLocked(models.User{TOTPLockedUntil: &earlier}, now), "lockout over")
+
+	assert.Equal(t, countWrongCode, secondFactorOutcome(models.User{}, ErrInvalidTOTPCode))
+	assert.Equal(t, countWrongCode, secondFactorOutcome(models.User{TOTPFailedAttempts: 3}, ErrInvalidTOTPCode))
+	assert.Equal(t, resetWrongCodes, secondFactorOutcome(models.User{TOTPFailedAttempts: 3}, nil))
+	assert.Equal(t, keepWrongCodes, secondFactorOutcome(models.User{}, nil))
+	assert.Equal(t, keepWrongCodes, secondFactorOutcome(models.User{TOTPFailedAttempts: 3}, errors.New("connection reset")))
+}
+
+func TestTOTPCodeIsNotAcceptedTwice(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+
+	// The step was already used, so the code is refused before it is recorded.
+	user := models.User{TOTPSecret: secret, TOTPLastStep: totp.Step(now)}
+	assert.ErrorIs(t, useTOTPCode(user, code), ErrInvalidTOTPCode)
+}
+
+func TestLoginChallengeAttempts(t *testing.T) {
+	now := time.Now()
+	challenge := models.AccountToken{ExpiresAt: now.Add(time.Minute)}
+	for attempts := 0; attempts < maxLoginChallengeAttempts; attempts++ {
+		challenge.Attempts = attempts
+		assert.True(t, loginChallengeUsable(challenge, now), "after %d wrong codes", attempts)
+	}
+	challenge.Attempts = maxLoginChallengeAttempts
+	assert.False(t, loginChallengeUsable(challenge, now))
+
+	challenge.Attempts = 0
+	assert.False(t, loginChallengeUsable(challenge, now.Add(2*time.Minute)), "expired")
+	challenge.UsedAt = &now
+	assert.False(t, loginChallengeUsable(challenge, now), "used")
+}
@@ -1,10 +1,10 @@
-package services
-
-import (
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-)
-
-func CreateUser(user *models.User) error {
-	return database.DB.Create(user).Error
-}
+package services
+
+import (
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+)
+
+func CreateUser(user *models.User) error {
+	return database.DB.Create(user).Error
+}
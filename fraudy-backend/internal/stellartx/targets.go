@@ -0,0 +1,118 @@
+// Package stellartx extracts the accounts a Stellar transaction touches.
+package stellartx
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	RoleDestination = "destination"
+	RoleIssuer      = "issuer"
+)
+
+// Target is an account referenced by one operation of a transaction.
+type Target struct {
+	Operation int
+	Role      string // destination, issuer
+	Address   string
+}
+
+// Decode parses a base64 transaction envelope. Fee bump envelopes yield their
+// inner transaction.
+func Decode(envelopeXDR string) (*txnbuild.Transaction, error) {
+	generic, err := txnbuild.TransactionFromXDR(strings.TrimSpace(envelopeXDR))
+	if err != nil {
+		return nil, err
+	}
+	if tx, ok := generic.Transaction(); ok {
+		return tx, nil
+	}
+	if feeBump, ok := generic.FeeBump(); ok {
+		return feeBump.InnerTransaction(), nil
+	}
+	return nil, errUnsupportedEnvelope
+}
+
+var errUnsupportedEnvelope = errors.New("unsupported envelope type")
+
+// Targets lists the destinations and asset issuers referenced by each
+// operation of tx. Muxed destinations are resolved to their G... account.
+func Targets(tx *txnbuild.Transaction) []Target {
+	var targets []Target
+	for i, op := range tx.Operations() {
+		destination := func(address string) {
+			targets = append(targets, Target{Operation: i, Role: RoleDestination, Address: BaseAccount(address)})
+		}
+		issuers := func(assets ...txnbuild.BasicAsset) {
+			for _, asset := range assets {
+				if asset == nil || asset.IsNative() || asset.GetIssuer() == "" {
+					continue
+				}
+				targets = append(targets, Target{Operation: i, Role: RoleIssuer, Address: asset.GetIssuer()})
+			}
+		}
+
+		switch op := op.(type) {
+		case *txnbuild.Payment:
+			destination(op.Destination)
+			issuers(op.Asset)
+		case *txnbuild.CreateAccount:
+			destination(op.Destination)
+		case *txnbuild.PathPaymentStrictReceive:
+			destination(op.Destination)
+			issuers(op.SendAsset, op.DestAsset)
+			for _, asset := range op.Path {
+				issuers(asset)
+			}
+		case *txnbuild.PathPaymentStrictSend:
+			destination(op.Destination)
+			issuers(op.SendAsset, op.DestAsset)
+			for _, asset := range op.Path {
+				issuers(asset)
+			}
+		case *txnbuild.AccountMerge:
+			destination(op.Destination)
+		case *txnbuild.CreateClaimableBalance:
+			for _, claimant := range op.Destinations {
+				destination(claimant.Destination)
+			}
+			issuers(op.Asset)
+		case *txnbuild.ChangeTrust:
+			issuers(op.Line)
+		case *txnbuild.ManageSellOffer:
+			issuers(op.Selling, op.Buying)
+		case *txnbuild.ManageBuyOffer:
+			issuers(op.Selling, op.Buying)
+		}
+	}
+	return targets
+}
+
+// Destinations returns the distinct accounts that receive value in tx.
+func Destinations(tx *txnbuild.Transaction) []string {
+	seen := make(map[string]bool)
+	var destinations []string
+	for _, target := range Targets(tx) {
+		if target.Role == RoleDestination && !seen[target.Address] {
+			seen[target.Address] = true
+			destinations = append(destinations, target.Address)
+		}
+	}
+	return destinations
+}
+
+// BaseAccount resolves a muxed (M...) address to the G... account behind it.
+func BaseAccount(address string) string {
+	if !strings.HasPrefix(address, "M") {
+		return address
+	}
+	muxed, err := xdr.AddressToMuxedAccount(address)
+	if err != nil {
+		return address
+	}
+	return muxed.ToAccountId().Address()
+}
@@ -0,0 +1,56 @@
+package streaming
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	stellarnetwork "github.com/stellar/go/network"
+)
+
+// HorizonClient returns the Horizon client for an alert's network.
+func HorizonClient(network string) *horizonclient.Client {
+	if network == "public" {
+		return horizonclient.DefaultPublicNetClient
+	}
+	return horizonclient.DefaultTestNetClient
+}
+
+// NetworkPassphrase returns the passphrase transactions are signed with on network.
+func NetworkPassphrase(network string) string {
+	if network == "public" {
+		return stellarnetwork.PublicNetworkPassphrase
+	}
+	return stellarnetwork.TestNetworkPassphrase
+}
+
+func horizonCacheTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("HORIZON_CACHE_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return time.Minute
+}
+
+// CachedHorizon serves the result of a Horizon call from Redis while it is
+// younger than HORIZON_CACHE_TTL, so repeated page loads do not run into
+// Horizon's rate limits. Errors are never cached. The boolean reports whether
+// the value came from the cache.
+func CachedHorizon[T any](key string, fetch func() (T, error)) (T, bool, error) {
+	key = "horizon:" + key
+	if cached, err := RedisClient.Get(ctx, key).Result(); err == nil {
+		var value T
+		if json.Unmarshal([]byte(cached), &value) == nil {
+			return value, true, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, false, err
+	}
+	if valueJSON, err := json.Marshal(value); err == nil {
+		RedisClient.Set(ctx, key, valueJSON, horizonCacheTTL())
+	}
+	return value, false, nil
+}
@@ -1,231 +1,370 @@
-package streaming
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log"
-	"sync"
-	"time"
-
-	"fraudy-backend/internal/database"
-	"fraudy-backend/internal/models"
-	"fraudy-backend/internal/services"
-
-	"github.com/stellar/go/clients/horizonclient"
-	"github.com/stellar/go/protocols/horizon"
-)
-
-var (
-	monitoredWallets = make(map[string]string)
-	mu               sync.Mutex
-	failedTxCache    = make(map[string][]string)
-	failedTxLock     sync.Mutex
-)
-
-func getMonitoredWallets() (map[string]string, error) {
-	var alerts []models.Alert
-	result := database.DB.Select("wallet_id, rule_type").Find(&alerts)
-	if result.Error != nil {
-		return nil, result.Error
-	}
-
-	walletRules := make(map[string]string)
-	for _, alert := range alerts {
-		walletRules[alert.WalletID] = alert.RuleType
-	}
-
-	return walletRules, nil
-}
-
-func MonitorNewWallets(ctx context.Context) {
-	for {
-		wallets, err := getMonitoredWallets()
-		if err != nil {
-			log.Printf("❌ Error fetching monitored WalletIDs: %v\n", err)
-			time.Sleep(10 * time.Second)
-			continue
-		}
-
-		mu.Lock()
-		for wallet, ruleType := range wallets {
-			if _, exists := monitoredWallets[wallet]; !exists {
-				monitoredWallets[wallet] = ruleType
-				go StreamTransactionsForWallet(ctx, wallet, ruleType)
-			}
-		}
-		mu.Unlock()
-		time.Sleep(10 * time.Second)
-
-		processStoredTransactions()
-	}
-}
-
-func StreamTransactionsForWallet(ctx context.Context, wallet string, ruleType string) {
-	client := horizonclient.DefaultTestNetClient
-	request := horizonclient.TransactionRequest{
-		ForAccount:    wallet,
-		Cursor:        "now",
-		Order:         horizonclient.OrderAsc,
-		IncludeFailed: true,
-	}
-
-	fmt.Printf("🛰️ Now monitoring transactions for WalletID: %s with rule: %s\n", wallet, ruleType)
-	err := client.StreamTransactions(ctx, request, func(tx horizon.Transaction) {
-		fmt.Printf("🔄 New Transaction: %s | Account: %s\n", tx.Hash, tx.Account)
-
-		exists, _ := RedisClient.Exists(context.Background(), fmt.Sprintf("transaction:%s", tx.Hash)).Result()
-		if exists == 0 {
-			err := storeTransaction(tx)
-			if err != nil {
-				log.Printf("❌ Error storing transaction in Redis: %v\n", err)
-			}
-		} else {
-			fmt.Printf("⚠️ Transaction %s already exists in Redis, skipping...\n", tx.Hash)
-		}
-	})
-
-	if err != nil {
-		log.Printf("❌ Error streaming transactions for WalletID %s: %v\n", wallet, err)
-	}
-}
-
-func storeTransaction(tx horizon.Transaction) error {
-	txJSON, err := json.Marshal(tx)
-	if err != nil {
-		fmt.Println("❌ Error serializing transaction:", err)
-		return err
-	}
-
-	key := fmt.Sprintf("transaction:%s", tx.Hash)
-	err = RedisClient.Set(context.Background(), key, txJSON, 30*time.Minute).Err()
-	if err != nil {
-		fmt.Println("❌ Error storing transaction in Redis:", err)
-	} else {
-		fmt.Printf("✅ Stored transaction in Redis: %s\n", tx.Hash)
-	}
-	return err
-}
-
-func processStoredTransactions() {
-	ctx := context.Background()
-	keys, err := RedisClient.Keys(ctx, "transaction:*").Result()
-	if err != nil {
-		log.Println("❌ Error fetching stored transactions from Redis:", err)
-		return
-	}
-
-	if len(keys) == 0 {
-		fmt.Println("⚠️ No stored transactions found in Redis!")
-		return
-	}
-
-	walletRules, err := getMonitoredWallets()
-	if err != nil {
-		log.Println("❌ Error fetching wallet rules:", err)
-		return
-	}
-
-	for _, key := range keys {
-		txJSON, err := RedisClient.Get(ctx, key).Result()
-		if err != nil {
-			log.Printf("❌ Error retrieving transaction from Redis (Key: %s): %v\n", key, err)
-			continue
-		}
-
-		var tx horizon.Transaction
-		if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
-			log.Printf("❌ Error unmarshalling transaction JSON (Key: %s): %v\n", key, err)
-			continue
-		}
-
-		ruleType, exists := walletRules[tx.Account]
-		if !exists {
-			fmt.Printf("⚠️ No rule found for Wallet: %s\n", tx.Account)
-			continue
-		}
-
-		var userID int
-		database.DB.Table("alerts").Select("user_id").Where("wallet_id = ?", tx.Account).Scan(&userID)
-
-		switch ruleType {
-		case "doubleSpend":
-			detectDoubleSpend(tx)
-		case "highFailureRate":
-			detectHighFailureRate(tx, userID)
-		default:
-			fmt.Printf("⚠️ No fraud detection logic for rule: %s\n", ruleType)
-		}
-	}
-}
-
-func detectDoubleSpend(tx horizon.Transaction) {
-	fmt.Printf("🚨 Running Double Spend Detection for Transaction: %s\n", tx.Hash)
-}
-
-func detectHighFailureRate(tx horizon.Transaction, userID int) {
-	ctx := context.Background()
-	processedKey := fmt.Sprintf("processed_tx:%s", tx.Hash)
-	exists, _ := RedisClient.Exists(ctx, processedKey).Result()
-	if exists > 0 {
-		fmt.Printf("⚠️ Skipping already processed transaction: %s\n", tx.Hash)
-		return
-	}
-	RedisClient.Set(ctx, processedKey, "processed", 10*time.Minute)
-
-	failedTxLock.Lock()
-	defer failedTxLock.Unlock()
-
-	account := tx.Account
-
-	if !tx.Successful {
-		failedTxCache[account] = append(failedTxCache[account], tx.Hash)
-		fmt.Printf("❌ Failed Transaction Detected: %s | Account: %s | Total Failures: %d\n",
-			tx.Hash, account, len(failedTxCache[account]))
-	}
-
-	if len(failedTxCache[account]) >= 10 {
-		fmt.Printf("🚨 HIGH FAILURE RATE DETECTED! Account: %s | Failed Tx Count: %d\n",
-			account, len(failedTxCache[account]))
-
-		// Convert to JSON array string
-		preferencesJSON, err := json.Marshal(failedTxCache[account])
-		if err != nil {
-			fmt.Println("❌ Error marshalling notification preferences:", err)
-			return
-		}
-
-		fraud := models.FraudActivity{
-			Account:      account,
-			Type:         "highFailureRate",
-			FailureCount: len(failedTxCache[account]),
-			Flag:         "Medium",
-		}
-		database.DB.Create(&fraud)
-
-		// Fetch user's email config
-		var config models.NotificationConfig
-		result := database.DB.Where("user_id = ? AND notification_type = ?", userID, "email").First(&config)
-		if result.Error != nil {
-			fmt.Printf("⚠️ No email config found for user ID %d\n", userID)
-			return
-		}
-
-		alert := models.Alert{
-			UserID:                 userID,
-			AlertName:              "High Failure Rate Alert",
-			RuleType:               "highFailureRate",
-			WalletID:               account,
-			Flag:                	"Medium",
-			NotificationPreferences: string(preferencesJSON),
-		}
-
-		err = services.SendEmailNotification(userID, alert)
-		if err != nil {
-			fmt.Println("❌ Failed to send email notification:", err)
-		}
-
-		failedTxCache[account] = nil
-	}
-}
-
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/events"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/services"
+	"fraudy-backend/internal/stellartx"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+)
+
+// watchedWallet is a wallet on the network an alert watches it on. The same
+// address can exist on both networks with unrelated histories.
+type watchedWallet struct {
+	Wallet  string
+	Network string
+}
+
+var (
+	monitoredWallets = make(map[watchedWallet][]string)
+	mu               sync.Mutex
+	failedTxCache    = make(map[watchedWallet][]string)
+	failedTxLock     sync.Mutex
+)
+
+// getMonitoredWallets maps each watched wallet and network to the distinct
+// rules its alerts use.
+func getMonitoredWallets() (map[watchedWallet][]string, error) {
+	var alerts []models.Alert
+	result := database.DB.Select("wallet_id, network, rule_type").Find(&alerts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	walletRules := make(map[watchedWallet][]string)
+	for _, alert := range alerts {
+		key := watchedWallet{Wallet: alert.WalletID, Network: alert.Network}
+		if !slices.Contains(walletRules[key], alert.RuleType) {
+			walletRules[key] = append(walletRules[key], alert.RuleType)
+		}
+	}
+
+	return walletRules, nil
+}
+
+func MonitorNewWallets(ctx context.Context) {
+	for {
+		wallets, err := getMonitoredWallets()
+		if err != nil {
+			log.Printf("❌ Error fetching monitored WalletIDs: %v\n", err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+
+		mu.Lock()
+		for wallet, rules := range wallets {
+			if _, exists := monitoredWallets[wallet]; !exists {
+				go StreamTransactionsForWallet(ctx, wallet.Wallet, wallet.Network, strings.Join(rules, ", "))
+			}
+			monitoredWallets[wallet] = rules
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Second)
+
+		processStoredTransactions()
+	}
+}
+
+func StreamTransactionsForWallet(ctx context.Context, wallet string, network string, ruleType string) {
+	client := HorizonClient(network)
+	request := horizonclient.TransactionRequest{
+		ForAccount:    wallet,
+		Cursor:        "now",
+		Order:         horizonclient.OrderAsc,
+		IncludeFailed: true,
+	}
+
+	fmt.Printf("🛰️ Now monitoring transactions for WalletID: %s on %s with rule: %s\n", wallet, network, ruleType)
+	publishStreamStatus(wallet, network, "connected", nil)
+	err := client.StreamTransactions(ctx, request, func(tx horizon.Transaction) {
+		fmt.Printf("🔄 New Transaction: %s | Account: %s\n", tx.Hash, tx.Account)
+
+		exists, _ := RedisClient.Exists(context.Background(), transactionKey(network, tx.Hash)).Result()
+		if exists == 0 {
+			err := storeTransaction(network, tx)
+			if err != nil {
+				log.Printf("❌ Error storing transaction in Redis: %v\n", err)
+			}
+		} else {
+			fmt.Printf("⚠️ Transaction %s already exists in Redis, skipping...\n", tx.Hash)
+		}
+	})
+
+	if err != nil {
+		log.Printf("❌ Error streaming transactions for WalletID %s on %s: %v\n", wallet, network, err)
+	}
+	publishStreamStatus(wallet, network, "disconnected", err)
+}
+
+// publishStreamStatus tells every organization with an alert on wallet and
+// network that its transaction stream connected or stopped.
+func publishStreamStatus(wallet string, network string, status string, streamErr error) {
+	var organizationIDs []uint
+	database.DB.Model(&models.Alert{}).Where("wallet_id = ? AND network = ?", wallet, network).
+		Distinct().Pluck("organization_id", &organizationIDs)
+
+	data := map[string]string{"wallet_id": wallet, "network": network, "status": status}
+	if streamErr != nil {
+		data["error"] = streamErr.Error()
+	}
+	for _, organizationID := range organizationIDs {
+		events.PublishToOrganization(organizationID, events.StreamStatusChanged, data)
+	}
+}
+
+// transactionKey is where a streamed transaction waits in Redis. It names
+// the network so processing can match it to the alerts on that network.
+func transactionKey(network string, hash string) string {
+	return fmt.Sprintf("transaction:%s:%s", network, hash)
+}
+
+// transactionTTL is how long a stored transaction stays in Redis, and so how
+// long it can be picked up again for processing.
+const transactionTTL = 30 * time.Minute
+
+func storeTransaction(network string, tx horizon.Transaction) error {
+	txJSON, err := json.Marshal(tx)
+	if err != nil {
+		fmt.Println("❌ Error serializing transaction:", err)
+		return err
+	}
+
+	key := transactionKey(network, tx.Hash)
+	err = RedisClient.Set(context.Background(), key, txJSON, transactionTTL).Err()
+	if err != nil {
+		fmt.Println("❌ Error storing transaction in Redis:", err)
+	} else {
+		fmt.Printf("✅ Stored transaction in Redis: %s\n", tx.Hash)
+	}
+	return err
+}
+
+func processStoredTransactions() {
+	ctx := context.Background()
+	keys, err := RedisClient.Keys(ctx, "transaction:*").Result()
+	if err != nil {
+		log.Println("❌ Error fetching stored transactions from Redis:", err)
+		return
+	}
+
+	if len(keys) == 0 {
+		fmt.Println("⚠️ No stored transactions found in Redis!")
+		return
+	}
+
+	walletRules, err := getMonitoredWallets()
+	if err != nil {
+		log.Println("❌ Error fetching wallet rules:", err)
+		return
+	}
+
+	for _, key := range keys {
+		txJSON, err := RedisClient.Get(ctx, key).Result()
+		if err != nil {
+			log.Printf("❌ Error retrieving transaction from Redis (Key: %s): %v\n", key, err)
+			continue
+		}
+
+		var tx horizon.Transaction
+		if err := json.Unmarshal([]byte(txJSON), &tx); err != nil {
+			log.Printf("❌ Error unmarshalling transaction JSON (Key: %s): %v\n", key, err)
+			continue
+		}
+
+		parts := strings.SplitN(key, ":", 3)
+		if len(parts) != 3 {
+			// Stored before keys named the network; it expires on its own.
+			continue
+		}
+		network := parts[1]
+
+		rules, exists := walletRules[watchedWallet{Wallet: tx.Account, Network: network}]
+		if !exists {
+			fmt.Printf("⚠️ No rule found for Wallet: %s on %s\n", tx.Account, network)
+			continue
+		}
+
+		for _, ruleType := range rules {
+			switch ruleType {
+			case "doubleSpend":
+				detectDoubleSpend(tx)
+			case "highFailureRate":
+				detectHighFailureRate(network, tx)
+			case "listPayment":
+				detectListPayment(network, tx)
+			default:
+				fmt.Printf("⚠️ No fraud detection logic for rule: %s\n", ruleType)
+			}
+		}
+	}
+}
+
+func detectDoubleSpend(tx horizon.Transaction) {
+	fmt.Printf("🚨 Running Double Spend Detection for Transaction: %s\n", tx.Hash)
+}
+
+func detectHighFailureRate(network string, tx horizon.Transaction) {
+	ctx := context.Background()
+	processedKey := fmt.Sprintf("processed_tx:%s:%s", network, tx.Hash)
+	exists, _ := RedisClient.Exists(ctx, processedKey).Result()
+	if exists > 0 {
+		fmt.Printf("⚠️ Skipping already processed transaction: %s\n", tx.Hash)
+		return
+	}
+	RedisClient.Set(ctx, processedKey, "processed", 10*time.Minute)
+
+	failedTxLock.Lock()
+	defer failedTxLock.Unlock()
+
+	account := tx.Account
+	wallet := watchedWallet{Wallet: account, Network: network}
+
+	if !tx.Successful {
+		failedTxCache[wallet] = append(failedTxCache[wallet], tx.Hash)
+		fmt.Printf("❌ Failed Transaction Detected: %s | Account: %s | Total Failures: %d\n",
+			tx.Hash, account, len(failedTxCache[wallet]))
+	}
+
+	if len(failedTxCache[wallet]) >= 10 {
+		fmt.Printf("🚨 HIGH FAILURE RATE DETECTED! Account: %s | Failed Tx Count: %d\n",
+			account, len(failedTxCache[wallet]))
+
+		hashesJSON, err := json.Marshal(failedTxCache[wallet])
+		if err != nil {
+			fmt.Println("❌ Error marshalling failed transaction hashes:", err)
+			return
+		}
+
+		fraud := models.FraudActivity{
+			Account:           account,
+			Type:              "highFailureRate",
+			TransactionHash:   tx.Hash,
+			TransactionHashes: string(hashesJSON),
+			FailureCount:      len(failedTxCache[wallet]),
+			Flag:              "Medium",
+			Network:           network,
+		}
+		recordFraudForAlerts(fraud)
+
+		failedTxCache[wallet] = nil
+	}
+}
+
+// detectListPayment flags successful transactions from a watched wallet that
+// pay an account on the list referenced by a listPayment alert.
+func detectListPayment(network string, tx horizon.Transaction) {
+	ctx := context.Background()
+	// Remember the transaction for as long as it is stored, or a later pass
+	// would record its cases again.
+	processedKey := fmt.Sprintf("processed_list_tx:%s:%s", network, tx.Hash)
+	if set, _ := RedisClient.SetNX(ctx, processedKey, "processed", transactionTTL).Result(); !set {
+		return
+	}
+	if !tx.Successful {
+		return
+	}
+
+	decoded, err := stellartx.Decode(tx.EnvelopeXdr)
+	if err != nil {
+		log.Printf("❌ Error decoding transaction %s: %v\n", tx.Hash, err)
+		return
+	}
+	destinations := stellartx.Destinations(decoded)
+	if len(destinations) == 0 {
+		return
+	}
+
+	var alerts []models.Alert
+	err = database.DB.Where("wallet_id = ? AND network = ? AND rule_type = ? AND list_id IS NOT NULL", tx.Account, network, "listPayment").
+		Find(&alerts).Error
+	if err != nil {
+		log.Printf("❌ Error fetching list alerts for %s: %v\n", tx.Account, err)
+		return
+	}
+
+	for _, alert := range alerts {
+		listed, err := services.ListedAddresses(*alert.ListID, destinations)
+		if err != nil {
+			log.Printf("❌ Error checking list %d: %v\n", *alert.ListID, err)
+			continue
+		}
+		for _, destination := range listed {
+			fmt.Printf("🚨 PAYMENT TO LISTED ACCOUNT! Account: %s | Destination: %s | List: %d\n", tx.Account, destination, *alert.ListID)
+			recordFraudForAlert(alert, models.FraudActivity{
+				Account:           tx.Account,
+				Type:              "listPayment",
+				TransactionHash:   tx.Hash,
+				TransactionHashes: fmt.Sprintf("[%q]", tx.Hash),
+				Sequence:          fmt.Sprint(tx.AccountSequence),
+				Counterparty:      destination,
+				Flag:              alert.Flag,
+			})
+		}
+	}
+}
+
+// recordFraudForAlerts fans a detected activity out to every alert watching the
+// account on its network with the same rule, so each owner gets their own case
+// and notification.
+func recordFraudForAlerts(fraud models.FraudActivity) {
+	var alerts []models.Alert
+	result := database.DB.Where("wallet_id = ? AND network = ? AND rule_type = ?", fraud.Account, fraud.Network, fraud.Type).
+		Find(&alerts)
+	if result.Error != nil {
+		log.Printf("❌ Error fetching alerts for %s: %v\n", fraud.Account, result.Error)
+		return
+	}
+	if len(alerts) == 0 {
+		fmt.Printf("⚠️ No %s alert found for Wallet: %s\n", fraud.Type, fraud.Account)
+		return
+	}
+
+	for _, alert := range alerts {
+		recordFraudForAlert(alert, fraud)
+	}
+}
+
+// recordFraudForAlert saves the activity as a case for the alert's
+// organization and notifies it, unless the account or its counterparty is on
+// one of the organization's allowlists.
+func recordFraudForAlert(alert models.Alert, fraud models.FraudActivity) {
+	if services.IsAllowlisted(alert.OrganizationID, fraud.Account, fraud.Counterparty) {
+		fmt.Printf("🔕 Suppressed %s alert %d: allowlisted counterparty\n", fraud.Type, alert.ID)
+		return
+	}
+
+	activity := fraud
+	activity.AlertID = alert.ID
+	activity.UserID = alert.UserID
+	activity.OrganizationID = alert.OrganizationID
+	activity.Network = alert.Network
+	if activity.Flag == "" {
+		activity.Flag = "Medium"
+	}
+	if err := database.DB.Create(&activity).Error; err != nil {
+		log.Printf("❌ Error saving fraud activity for alert %d: %v\n", alert.ID, err)
+		return
+	}
+	events.PublishToOrganization(alert.OrganizationID, events.FraudActivityCreated, activity)
+
+	services.NotifyAlert(alert, activity)
+	events.PublishToOrganization(alert.OrganizationID, events.AlertTriggered, map[string]interface{}{
+		"alert_id":          alert.ID,
+		"alert_name":        alert.AlertName,
+		"rule_type":         alert.RuleType,
+		"wallet_id":         alert.WalletID,
+		"fraud_activity_id": activity.ID,
+	})
+}
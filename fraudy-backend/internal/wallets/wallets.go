@@ -0,0 +1,185 @@
+// Package wallets assembles what Fraudy knows about a Stellar account: its
+// on-chain state and history from Horizon, and the alerts and fraud
+// activities an organization has on it.
+package wallets
+
+import (
+	"fmt"
+	"time"
+
+	"fraudy-backend/internal/database"
+	"fraudy-backend/internal/models"
+	"fraudy-backend/internal/streaming"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/protocols/horizon/operations"
+)
+
+type Transaction struct {
+	Hash           string    `json:"hash"`
+	Ledger         int32     `json:"ledger"`
+	CreatedAt      time.Time `json:"created_at"`
+	SourceAccount  string    `json:"source_account"`
+	Successful     bool      `json:"successful"`
+	FeeCharged     int64     `json:"fee_charged"`
+	OperationCount int32     `json:"operation_count"`
+	Memo           string    `json:"memo,omitempty"`
+}
+
+// Operation flattens the Horizon operation types into the fields the wallet
+// page shows. From, To, Amount and Asset are only set for value transfers.
+type Operation struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	TransactionHash string    `json:"transaction_hash"`
+	CreatedAt       time.Time `json:"created_at"`
+	SourceAccount   string    `json:"source_account"`
+	Successful      bool      `json:"successful"`
+	From            string    `json:"from,omitempty"`
+	To              string    `json:"to,omitempty"`
+	Amount          string    `json:"amount,omitempty"`
+	Asset           string    `json:"asset,omitempty"`
+}
+
+// Chain is the part of the wallet details that comes from Horizon.
+type Chain struct {
+	Exists        bool                      `json:"exists"`
+	Sequence      int64                     `json:"sequence,string"`
+	SubentryCount int32                     `json:"subentry_count"`
+	HomeDomain    string                    `json:"home_domain"`
+	Balances      []horizon.Balance         `json:"balances"`
+	Signers       []horizon.Signer          `json:"signers"`
+	Thresholds    horizon.AccountThresholds `json:"thresholds"`
+	Flags         horizon.AccountFlags      `json:"flags"`
+	Transactions  []Transaction             `json:"transactions"`
+	Operations    []Operation               `json:"operations"`
+	FetchedAt     time.Time                 `json:"fetched_at"`
+}
+
+type Details struct {
+	Account string `json:"account"`
+	Network string `json:"network"`
+	Chain
+	Cached          bool                   `json:"cached"`
+	FraudActivities []models.FraudActivity `json:"fraud_activities"`
+	Alerts          []models.Alert         `json:"alerts"`
+}
+
+// AssetName renders an asset as XLM or CODE:ISSUER.
+func AssetName(asset base.Asset) string {
+	if asset.Type == "native" {
+		return "XLM"
+	}
+	return asset.Code + ":" + asset.Issuer
+}
+
+// ConvertOperation flattens a Horizon operation record.
+func ConvertOperation(record operations.Operation) Operation {
+	b := record.GetBase()
+	op := Operation{
+		ID:              b.ID,
+		Type:            b.Type,
+		TransactionHash: b.TransactionHash,
+		CreatedAt:       b.LedgerCloseTime,
+		SourceAccount:   b.SourceAccount,
+		Successful:      b.TransactionSuccessful,
+	}
+	switch record := record.(type) {
+	case operations.Payment:
+		op.From, op.To, op.Amount, op.Asset = record.From, record.To, record.Amount, AssetName(record.Asset)
+	case operations.PathPayment:
+		op.From, op.To, op.Amount, op.Asset = record.From, record.To, record.Amount, AssetName(record.Asset)
+	case operations.PathPaymentStrictSend:
+		op.From, op.To, op.Amount, op.Asset = record.From, record.To, record.Amount, AssetName(record.Asset)
+	case operations.CreateAccount:
+		op.From, op.To, op.Amount, op.Asset = record.Funder, record.Account, record.StartingBalance, "XLM"
+	case operations.AccountMerge:
+		op.From, op.To = record.Account, record.Into
+	}
+	return op
+}
+
+func fetchChain(client *horizonclient.Client, account string, limit uint) (Chain, error) {
+	chain := Chain{FetchedAt: time.Now(), Balances: []horizon.Balance{}, Signers: []horizon.Signer{},
+		Transactions: []Transaction{}, Operations: []Operation{}}
+
+	detail, err := client.AccountDetail(horizonclient.AccountRequest{AccountID: account})
+	if horizonclient.IsNotFoundError(err) {
+		return chain, nil
+	}
+	if err != nil {
+		return chain, fmt.Errorf("fetching account from Horizon: %v", err)
+	}
+	chain.Exists = true
+	chain.Sequence = detail.Sequence
+	chain.SubentryCount = detail.SubentryCount
+	chain.HomeDomain = detail.HomeDomain
+	chain.Balances = detail.Balances
+	chain.Signers = detail.Signers
+	chain.Thresholds = detail.Thresholds
+	chain.Flags = detail.Flags
+
+	txPage, err := client.Transactions(horizonclient.TransactionRequest{
+		ForAccount:    account,
+		Order:         horizonclient.OrderDesc,
+		Limit:         limit,
+		IncludeFailed: true,
+	})
+	if err != nil {
+		return chain, fmt.Errorf("fetching transactions from Horizon: %v", err)
+	}
+	for _, tx := range txPage.Embedded.Records {
+		chain.Transactions = append(chain.Transactions, Transaction{
+			Hash:           tx.Hash,
+			Ledger:         tx.Ledger,
+			CreatedAt:      tx.LedgerCloseTime,
+			SourceAccount:  tx.Account,
+			Successful:     tx.Successful,
+			FeeCharged:     tx.FeeCharged,
+			OperationCount: tx.OperationCount,
+			Memo:           tx.Memo,
+		})
+	}
+
+	opPage, err := client.Operations(horizonclient.OperationRequest{
+		ForAccount:    account,
+		Order:         horizonclient.OrderDesc,
+		Limit:         limit,
+		IncludeFailed: true,
+	})
+	if err != nil {
+		return chain, fmt.Errorf("fetching operations from Horizon: %v", err)
+	}
+	for _, record := range opPage.Embedded.Records {
+		chain.Operations = append(chain.Operations, ConvertOperation(record))
+	}
+	return chain, nil
+}
+
+// GetDetails returns the wallet details of account on network as seen by
+// organizationID. The Horizon part is cached; the organization's alerts and
+// activities on network are always fresh.
+func GetDetails(account string, network string, organizationID uint, limit uint) (Details, error) {
+	details := Details{Account: account, Network: network}
+
+	key := fmt.Sprintf("%s:wallet:%s:%d", network, account, limit)
+	chain, cached, err := streaming.CachedHorizon(key, func() (Chain, error) {
+		return fetchChain(streaming.HorizonClient(network), account, limit)
+	})
+	if err != nil {
+		return details, err
+	}
+	details.Chain = chain
+	details.Cached = cached
+
+	err = database.DB.Where("organization_id = ? AND network = ? AND (account = ? OR counterparty = ?)", organizationID, network, account, account).
+		Order("created_at DESC").Limit(int(limit)).Find(&details.FraudActivities).Error
+	if err != nil {
+		return details, err
+	}
+	err = database.DB.Where("organization_id = ? AND network = ? AND wallet_id = ?", organizationID, network, account).
+		Find(&details.Alerts).Error
+	return details, err
+}
@@ -0,0 +1,137 @@
+// Package webauth implements Stellar web authentication (SEP-10): the server
+// hands out a challenge transaction, the wallet signs it, and a correctly
+// signed challenge proves control of the Stellar account.
+package webauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"fraudy-backend/internal/streaming"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+const challengeTTL = 5 * time.Minute
+
+var (
+	ErrNotConfigured    = errors.New("Stellar sign-in is not configured")
+	ErrInvalidChallenge = errors.New("invalid or expired challenge transaction")
+	ErrChallengeReused  = errors.New("challenge transaction was already used")
+)
+
+// config is read from the environment:
+//
+//	SEP10_SIGNING_SECRET   secret seed the server signs challenges with
+//	SEP10_HOME_DOMAIN      domain wallets see in the challenge (default localhost)
+//	SEP10_WEB_AUTH_DOMAIN  domain serving the endpoint (default SEP10_HOME_DOMAIN)
+//	SEP10_NETWORK          "public" or "testnet" (default testnet)
+type config struct {
+	signer        *keypair.Full
+	homeDomain    string
+	webAuthDomain string
+	network       string
+}
+
+func loadConfig() (config, error) {
+	seed := os.Getenv("SEP10_SIGNING_SECRET")
+	if seed == "" {
+		return config{}, ErrNotConfigured
+	}
+	signer, err := keypair.ParseFull(seed)
+	if err != nil {
+		return config{}, fmt.Errorf("❌ Invalid SEP10_SIGNING_SECRET: %v", err)
+	}
+	cfg := config{
+		signer:        signer,
+		homeDomain:    os.Getenv("SEP10_HOME_DOMAIN"),
+		webAuthDomain: os.Getenv("SEP10_WEB_AUTH_DOMAIN"),
+		network:       os.Getenv("SEP10_NETWORK"),
+	}
+	if cfg.homeDomain == "" {
+		cfg.homeDomain = "localhost"
+	}
+	if cfg.webAuthDomain == "" {
+		cfg.webAuthDomain = cfg.homeDomain
+	}
+	return cfg, nil
+}
+
+// Challenge is what a wallet signs to sign in.
+type Challenge struct {
+	Transaction       string `json:"transaction"`
+	NetworkPassphrase string `json:"network_passphrase"`
+}
+
+// NewChallenge builds a challenge transaction for account, valid for five
+// minutes.
+func NewChallenge(account string) (Challenge, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return Challenge{}, err
+	}
+	passphrase := streaming.NetworkPassphrase(cfg.network)
+	tx, err := txnbuild.BuildChallengeTx(cfg.signer.Seed(), account, cfg.webAuthDomain, cfg.homeDomain,
+		passphrase, challengeTTL, nil)
+	if err != nil {
+		return Challenge{}, fmt.Errorf("%w: %v", ErrInvalidChallenge, err)
+	}
+	encoded, err := tx.Base64()
+	if err != nil {
+		return Challenge{}, err
+	}
+	return Challenge{Transaction: encoded, NetworkPassphrase: passphrase}, nil
+}
+
+// Verify checks a signed challenge and returns the account it proves control
+// of. Existing accounts must be signed with at least their medium threshold
+// of signer weight; accounts that are not funded yet by their master key.
+// Each challenge is accepted once.
+func Verify(challengeTx string) (string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	passphrase := streaming.NetworkPassphrase(cfg.network)
+	serverAccount := cfg.signer.Address()
+	homeDomains := []string{cfg.homeDomain}
+
+	tx, account, _, _, err := txnbuild.ReadChallengeTx(challengeTx, serverAccount, passphrase, cfg.webAuthDomain, homeDomains)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidChallenge, err)
+	}
+
+	detail, err := streaming.HorizonClient(cfg.network).AccountDetail(horizonclient.AccountRequest{AccountID: account})
+	switch {
+	case horizonclient.IsNotFoundError(err):
+		_, err = txnbuild.VerifyChallengeTxSigners(challengeTx, serverAccount, passphrase, cfg.webAuthDomain,
+			homeDomains, account)
+	case err != nil:
+		return "", fmt.Errorf("❌ Error loading account %s: %v", account, err)
+	default:
+		_, err = txnbuild.VerifyChallengeTxThreshold(challengeTx, serverAccount, passphrase, cfg.webAuthDomain,
+			homeDomains, txnbuild.Threshold(detail.Thresholds.MedThreshold), detail.SignerSummary())
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidChallenge, err)
+	}
+
+	hash, err := tx.HashHex(passphrase)
+	if err != nil {
+		return "", err
+	}
+	// The challenge stays valid until it times out, so remember it until then.
+	fresh, err := streaming.RedisClient.SetNX(context.Background(), "sep10:used:"+hash, account, challengeTTL).Result()
+	if err != nil {
+		return "", fmt.Errorf("❌ Error recording challenge: %v", err)
+	}
+	if !fresh {
+		return "", ErrChallengeReused
+	}
+	return account, nil
+}
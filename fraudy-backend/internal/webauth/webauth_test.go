@@ -0,0 +1,31 @@
+package webauth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("SEP10_SIGNING_SECRET", "")
+	_, err := loadConfig()
+	assert.ErrorIs(t, err, ErrNotConfigured)
+
+	t.Setenv("SEP10_SIGNING_SECRET", "not a seed")
+	_, err = loadConfig()
+	assert.Error(t, err)
+
+	t.Setenv("SEP10_SIGNING_SECRET", "SAAQEAYEAUDAOCAJBIFQYDIOB4IBCEQTCQKRMFYYDENBWHA5DYPSBF5K")
+	t.Setenv("SEP10_HOME_DOMAIN", "fraudy.example.com")
+	t.Setenv("SEP10_WEB_AUTH_DOMAIN", "")
+	cfg, err := loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "fraudy.example.com", cfg.homeDomain)
+	assert.Equal(t, "fraudy.example.com", cfg.webAuthDomain)
+
+	t.Setenv("SEP10_WEB_AUTH_DOMAIN", "api.fraudy.example.com")
+	cfg, err = loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "api.fraudy.example.com", cfg.webAuthDomain)
+}
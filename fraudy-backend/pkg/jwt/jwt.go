@@ -0,0 +1,205 @@
+// Package jwt holds the keys access tokens are signed with.
+//
+// Every token names its signing key in the "kid" header, so a new key can be
+// made active while tokens signed with the previous one stay valid until
+// they expire. Asymmetric keys (RS256, EdDSA) let others verify tokens with
+// the public keys published as a JWKS; HMAC keys (HS256) are never published.
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+)
+
+const minHMACKeyLength = 32
+
+// Key is one signing key of the keyring.
+type Key struct {
+	ID        string
+	Method    gojwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// Keyring holds the signing keys by ID and the ID used for new tokens.
+type Keyring struct {
+	activeID string
+	keys     map[string]*Key
+}
+
+// NewKeyring parses a comma separated list of "id:alg:value" entries. For
+// HS256 the value is a base64 secret of at least 32 bytes; for RS256 and
+// EdDSA it is the path of a PEM encoded private key. activeID must be one of
+// the listed IDs.
+func NewKeyring(spec string, activeID string) (*Keyring, error) {
+	keyring := &Keyring{activeID: activeID, keys: make(map[string]*Key)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid signing key entry, expected id:alg:value")
+		}
+		key, err := parseKey(parts[0], parts[1], parts[2])
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[key.ID] = key
+	}
+
+	if _, ok := keyring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not in the keyring", activeID)
+	}
+	return keyring, nil
+}
+
+// NewHMACKeyring holds a single HS256 secret with the ID "default", for
+// deployments that still configure one shared secret. Like HS256 keys in
+// NewKeyring, the secret must be at least 32 bytes.
+func NewHMACKeyring(secret string) (*Keyring, error) {
+	if secret == "" {
+		return nil, errors.New("signing secret is empty")
+	}
+	if len(secret) < minHMACKeyLength {
+		return nil, fmt.Errorf("signing secret must be at least %d bytes", minHMACKeyLength)
+	}
+	key := &Key{ID: "default", Method: gojwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+	return &Keyring{activeID: key.ID, keys: map[string]*Key{key.ID: key}}, nil
+}
+
+// parseKey never includes key material in its errors, so they are safe to log.
+func parseKey(id string, alg string, value string) (*Key, error) {
+	switch alg {
+	case gojwt.SigningMethodHS256.Alg():
+		secret, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(secret) < minHMACKeyLength {
+			return nil, fmt.Errorf("signing key %q must be at least %d bytes encoded as base64", id, minHMACKeyLength)
+		}
+		return &Key{ID: id, Method: gojwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+
+	case gojwt.SigningMethodRS256.Alg():
+		pemBytes, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("reading signing key %q: %v", id, err)
+		}
+		private, err := gojwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q is not an RSA private key", id)
+		}
+		if private.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("signing key %q must be at least 2048 bits", id)
+		}
+		return &Key{ID: id, Method: gojwt.SigningMethodRS256, signKey: private, verifyKey: &private.PublicKey}, nil
+
+	case gojwt.SigningMethodEdDSA.Alg():
+		pemBytes, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("reading signing key %q: %v", id, err)
+		}
+		parsed, err := gojwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		private, ok := parsed.(ed25519.PrivateKey)
+		if err != nil || !ok {
+			return nil, fmt.Errorf("signing key %q is not an Ed25519 private key", id)
+		}
+		return &Key{ID: id, Method: gojwt.SigningMethodEdDSA, signKey: private, verifyKey: private.Public()}, nil
+	}
+	return nil, fmt.Errorf("signing key %q uses unsupported algorithm %q, expected HS256, RS256 or EdDSA", id, alg)
+}
+
+// ActiveKeyID returns the ID of the key used for new tokens.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// Sign signs claims with the active key and names it in the kid header.
+func (k *Keyring) Sign(claims gojwt.Claims) (string, error) {
+	key := k.keys[k.activeID]
+	token := gojwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// Keyfunc returns the verification key named by token's kid header. The
+// token must use that key's algorithm, so an HMAC token cannot be forged
+// with a published public key.
+func (k *Keyring) Keyfunc(token *gojwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", id)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), id)
+	}
+	return key.verifyKey, nil
+}
+
+// JWK is the public part of a signing key (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet is the document served at a JWKS endpoint.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the keyring's asymmetric keys, active key
+// first and the rest by ID. HMAC keys are left out since they are secret.
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	add := func(key *Key) {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	add(k.keys[k.activeID])
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		if id != k.activeID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		add(k.keys[id])
+	}
+	return set
+}
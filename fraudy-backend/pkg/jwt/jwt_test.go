@@ -0,0 +1,145 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fraudy-backend/pkg/jwt"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldSecret = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32)))
+	newSecret = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32)))
+)
+
+func writePEM(t *testing.T, name string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func rsaKeyFile(t *testing.T) (string, *rsa.PrivateKey) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	return writePEM(t, "rsa.pem", der), private
+}
+
+func ed25519KeyFile(t *testing.T) string {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	return writePEM(t, "ed25519.pem", der)
+}
+
+func claims() gojwt.RegisteredClaims {
+	return gojwt.RegisteredClaims{Subject: "42", ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Minute))}
+}
+
+func parse(keyring *jwt.Keyring, token string) error {
+	_, err := gojwt.ParseWithClaims(token, &gojwt.RegisteredClaims{}, keyring.Keyfunc)
+	return err
+}
+
+func TestSignAndVerifyEachAlgorithm(t *testing.T) {
+	rsaPath, _ := rsaKeyFile(t)
+	edPath := ed25519KeyFile(t)
+	spec := "h1:HS256:" + oldSecret + ",r1:RS256:" + rsaPath + ",e1:EdDSA:" + edPath
+
+	for _, id := range []string{"h1", "r1", "e1"} {
+		keyring, err := jwt.NewKeyring(spec, id)
+		require.NoError(t, err, id)
+
+		token, err := keyring.Sign(claims())
+		require.NoError(t, err, id)
+		parsed, _, err := new(gojwt.Parser).ParseUnverified(token, &gojwt.RegisteredClaims{})
+		require.NoError(t, err, id)
+		assert.Equal(t, id, parsed.Header["kid"])
+		assert.NoError(t, parse(keyring, token), id)
+	}
+}
+
+func TestTokensOfPreviousKeyVerifyAfterRotation(t *testing.T) {
+	before, err := jwt.NewKeyring("k1:HS256:"+oldSecret, "k1")
+	require.NoError(t, err)
+	token, err := before.Sign(claims())
+	require.NoError(t, err)
+
+	after, err := jwt.NewKeyring("k1:HS256:"+oldSecret+",k2:HS256:"+newSecret, "k2")
+	require.NoError(t, err)
+	assert.NoError(t, parse(after, token))
+
+	retired, err := jwt.NewKeyring("k2:HS256:"+newSecret, "k2")
+	require.NoError(t, err)
+	assert.Error(t, parse(retired, token))
+}
+
+func TestKeyfuncRejectsAlgorithmConfusion(t *testing.T) {
+	rsaPath, private := rsaKeyFile(t)
+	keyring, err := jwt.NewKeyring("r1:RS256:"+rsaPath, "r1")
+	require.NoError(t, err)
+
+	// An HMAC token keyed with the published public key must not verify.
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	require.NoError(t, err)
+	forged := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = "r1"
+	token, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.NoError(t, err)
+	assert.Error(t, parse(keyring, token))
+
+	// Tokens without a known kid are rejected.
+	unnamed, err := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims()).SignedString(private)
+	require.NoError(t, err)
+	assert.Error(t, parse(keyring, unnamed))
+}
+
+func TestJWKSPublishesOnlyPublicKeys(t *testing.T) {
+	rsaPath, private := rsaKeyFile(t)
+	edPath := ed25519KeyFile(t)
+	keyring, err := jwt.NewKeyring("h1:HS256:"+oldSecret+",r1:RS256:"+rsaPath+",e1:EdDSA:"+edPath, "r1")
+	require.NoError(t, err)
+
+	set := keyring.JWKS()
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "r1", set.Keys[0].KeyID)
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(private.N.Bytes()), set.Keys[0].N)
+	assert.Equal(t, "AQAB", set.Keys[0].E)
+	assert.Equal(t, "e1", set.Keys[1].KeyID)
+	assert.Equal(t, "OKP", set.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", set.Keys[1].Curve)
+}
+
+func TestNewKeyringErrorsDoNotLeakKeys(t *testing.T) {
+	_, err := jwt.NewKeyring("k1:HS256:c2hvcnQ=", "k1")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "c2hvcnQ")
+
+	_, err = jwt.NewKeyring("k1:HS512:"+oldSecret, "k1")
+	assert.Error(t, err)
+	_, err = jwt.NewKeyring("k1:HS256:"+oldSecret, "k2")
+	assert.Error(t, err)
+	_, err = jwt.NewHMACKeyring("")
+	assert.Error(t, err)
+	_, err = jwt.NewHMACKeyring("short-secret")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "short-secret")
+	_, err = jwt.NewHMACKeyring(strings.Repeat("s", 32))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,188 @@
+// Package secrets implements envelope encryption for values stored at rest.
+//
+// Every value is encrypted with its own random data key using AES-256-GCM.
+// The data key is in turn encrypted ("wrapped") with a master key from the
+// keyring, so rotating the master key only requires re-wrapping data keys.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const prefix = "enc:v1:"
+
+// Keyring holds the master keys by ID and the ID used for new encryptions.
+type Keyring struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyring parses a comma separated list of "id:base64key" pairs. Each key
+// must decode to 32 bytes, and activeID must be one of the listed IDs.
+func NewKeyring(spec string, activeID string) (*Keyring, error) {
+	keyring := &Keyring{activeID: activeID, keys: make(map[string][]byte)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("invalid master key entry %q, expected id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes encoded as base64", id)
+		}
+		keyring.keys[id] = key
+	}
+
+	if _, ok := keyring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active master key %q is not in the keyring", activeID)
+	}
+	return keyring, nil
+}
+
+// ActiveKeyID returns the ID of the master key used for new encryptions.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// IsEncrypted reports whether value was produced by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func seal(key []byte, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key []byte, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+// Encrypt returns "enc:v1:<key id>:<wrapped data key>:<ciphertext>".
+// Empty values are returned unchanged.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrappedKey, err := seal(k.keys[k.activeID], dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + k.activeID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func (k *Keyring) unwrap(value string) (keyID string, dataKey []byte, ciphertext []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("malformed encrypted value")
+	}
+	keyID = parts[0]
+	masterKey, ok := k.keys[keyID]
+	if !ok {
+		return "", nil, nil, fmt.Errorf("master key %q is not in the keyring", keyID)
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, err
+	}
+	ciphertext, err = base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, err
+	}
+	dataKey, err = open(masterKey, wrappedKey)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("unwrapping data key: %v", err)
+	}
+	return keyID, dataKey, ciphertext, nil
+}
+
+// Decrypt reverses Encrypt. Values that were never encrypted are returned
+// unchanged so rows written before encryption was enabled keep working.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	_, dataKey, ciphertext, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("decrypting value: %v", err)
+	}
+	return string(plaintext), nil
+}
+
+// Rewrap re-encrypts the data key of value with the active master key and
+// encrypts values that are still plaintext. The boolean reports whether the
+// value changed.
+func (k *Keyring) Rewrap(value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+	if !IsEncrypted(value) {
+		encrypted, err := k.Encrypt(value)
+		return encrypted, err == nil, err
+	}
+
+	keyID, dataKey, ciphertext, err := k.unwrap(value)
+	if err != nil {
+		return value, false, err
+	}
+	if keyID == k.activeID {
+		return value, false, nil
+	}
+
+	wrappedKey, err := seal(k.keys[k.activeID], dataKey)
+	if err != nil {
+		return value, false, err
+	}
+	return prefix + k.activeID + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(ciphertext), true, nil
+}
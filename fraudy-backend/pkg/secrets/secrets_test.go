@@ -0,0 +1,68 @@
+package secrets_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"fraudy-backend/pkg/secrets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldMasterKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32)))
+	newMasterKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 32)))
+)
+
+func TestSecretsEncryptDecrypt(t *testing.T) {
+	keyring, err := secrets.NewKeyring("k1:"+oldMasterKey, "k1")
+	assert.NoError(t, err)
+
+	encrypted, err := keyring.Encrypt("smtp-password")
+	assert.NoError(t, err)
+	assert.True(t, secrets.IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "smtp-password")
+
+	decrypted, err := keyring.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp-password", decrypted)
+
+	// Legacy plaintext values are passed through untouched.
+	plaintext, err := keyring.Decrypt("https://hooks.slack.com/services/legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://hooks.slack.com/services/legacy", plaintext)
+}
+
+func TestSecretsRewrapWithNewMasterKey(t *testing.T) {
+	oldKeyring, _ := secrets.NewKeyring("k1:"+oldMasterKey, "k1")
+	encrypted, _ := oldKeyring.Encrypt("smtp-password")
+
+	rotated, err := secrets.NewKeyring("k1:"+oldMasterKey+",k2:"+newMasterKey, "k2")
+	assert.NoError(t, err)
+
+	rewrapped, changed, err := rotated.Rewrap(encrypted)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, strings.HasPrefix(rewrapped, "enc:v1:k2:"))
+
+	_, changed, _ = rotated.Rewrap(rewrapped)
+	assert.False(t, changed, "values under the active key should not be rewrapped again")
+
+	// Once rotated, the old master key can be dropped from the keyring.
+	newOnly, _ := secrets.NewKeyring("k2:"+newMasterKey, "k2")
+	decrypted, err := newOnly.Decrypt(rewrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, "smtp-password", decrypted)
+
+	_, err = newOnly.Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestSecretsKeyringValidation(t *testing.T) {
+	_, err := secrets.NewKeyring("k1:"+oldMasterKey, "missing")
+	assert.Error(t, err)
+
+	_, err = secrets.NewKeyring("k1:c2hvcnQ=", "k1")
+	assert.Error(t, err)
+}